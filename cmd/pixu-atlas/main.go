@@ -0,0 +1,128 @@
+// Command pixu-atlas packs PNG images into atlas pages ahead of time.
+//
+// Usage:
+//
+//	pixu-atlas [flags] <png files or directories>...
+//
+// It writes <name>_0.png, <name>_1.png... and <name>.json to the output
+// directory. Load them in a game with graphics.LoadAtlas("<dir>/<name>.json").
+//
+// Regions are named after the files without the .png extension. Files
+// found in a directory keep their path below it, so sprites/hero/idle.png
+// given as "sprites" becomes "hero/idle".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QOthman/Pixu/graphics/atlas"
+)
+
+func main() {
+	out := flag.String("o", ".", "output directory")
+	name := flag.String("name", "atlas", "base name of the output files")
+	maxSize := flag.Int("max", 2048, "largest page width and height")
+	padding := flag.Int("padding", 2, "empty pixels between images")
+	extrude := flag.Int("extrude", 1, "edge pixels repeated around each image")
+	pot := flag.Bool("pot", false, "round page sizes up to powers of two")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: pixu-atlas [flags] <png files or directories>...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	images, err := collect(flag.Args())
+	if err != nil {
+		fail(err)
+	}
+	if len(images) == 0 {
+		fail(fmt.Errorf("no PNG images found"))
+	}
+
+	packed, err := atlas.Pack(images, atlas.Options{
+		MaxWidth:   *maxSize,
+		MaxHeight:  *maxSize,
+		Padding:    *padding,
+		Extrude:    *extrude,
+		PowerOfTwo: *pot,
+	})
+	if err != nil {
+		fail(err)
+	}
+	if err := packed.Save(*out, *name); err != nil {
+		fail(err)
+	}
+	fmt.Printf("packed %d images into %d page(s) in %s\n", len(packed.Regions), len(packed.Pages), *out)
+}
+
+// Load every PNG named by the arguments, keyed by region name
+func collect(args []string) (map[string]image.Image, error) {
+	images := make(map[string]image.Image)
+	add := func(name, path string) error {
+		if _, ok := images[name]; ok {
+			return fmt.Errorf("two images named %q", name)
+		}
+		img, err := decode(path)
+		if err != nil {
+			return err
+		}
+		images[name] = img
+		return nil
+	}
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(strings.TrimSuffix(filepath.Base(arg), filepath.Ext(arg)), arg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".png") {
+				return err
+			}
+			rel, err := filepath.Rel(arg, path)
+			if err != nil {
+				return err
+			}
+			return add(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), path)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
+
+func decode(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "pixu-atlas:", err)
+	os.Exit(1)
+}
@@ -5,4 +5,7 @@ go 1.23.4
 require (
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20250301202403-da16c1255728
+	golang.org/x/image v0.30.0
 )
+
+require golang.org/x/text v0.28.0 // indirect
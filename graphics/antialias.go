@@ -0,0 +1,169 @@
+package graphics
+
+import "math"
+
+// Anti-aliasing comes in two kinds:
+//  - MSAA asks the window for multisampling. It is done by the GPU
+//    and smooths everything, but only on the window, not in render
+//    targets or the software backend.
+//  - SetAntiAlias adds a one pixel wide fringe fading to transparent
+//    around every filled shape and stroke. It works on every surface
+//    and backend and can be switched on and off between draws.
+
+// Samples per pixel asked for by Init, 0 for none
+var msaaSamples int
+
+// Whether shapes get a smoothed edge
+var antiAlias bool
+
+// SetMSAA sets the multisample count (4 is common) for the window
+// Call it before Init, it has no effect afterwards.
+func SetMSAA(samples int) {
+	msaaSamples = max(samples, 0)
+}
+
+// SetAntiAlias turns smooth shape edges on or off for everything drawn afterwards
+func SetAntiAlias(enabled bool) {
+	antiAlias = enabled
+}
+
+// IsAntiAlias reports whether shapes are drawn with smooth edges
+func IsAntiAlias() bool {
+	return antiAlias
+}
+
+// Width of the fringe in the units shape vertices are in (after the transform)
+func fringeWidth() float32 {
+	m := viewMatrix
+	scale := sqrt32(abs32(m[0]*m[4] - m[1]*m[3]))
+	if scale == 0 {
+		return 1
+	}
+	return 1 / scale
+}
+
+// fringeEdge is an edge used by a single triangle, so it lies on the outline
+type fringeEdge struct {
+	a, b   int     // vertex indices
+	normal Vector2 // pointing out of the shape
+}
+
+// Add a fringe around a triangle mesh
+// Edges used by only one triangle are the outline. Outline vertices
+// move in by half the fringe width and a copy with no alpha goes out
+// by the other half, so the shape keeps its size on screen and the
+// edge fades over one pixel. Shapes thinner than two fringes move in
+// less, down to not at all, so their solid core never disappears.
+// Vertices at the same position are treated as one, so meshes must
+// not have T-junctions.
+func addFringe(vertices []float32, indices []uint32) ([]float32, []uint32) {
+	const stride = 6
+	count := len(vertices) / stride
+	position := func(i int) Vector2 {
+		return Vector2{vertices[i*stride], vertices[i*stride+1]}
+	}
+
+	// One id per distinct position
+	ids := make([]int, count)
+	byPosition := make(map[Vector2]int, count)
+	for i := 0; i < count; i++ {
+		p := position(i)
+		id, ok := byPosition[p]
+		if !ok {
+			id = i
+			byPosition[p] = id
+		}
+		ids[i] = id
+	}
+
+	// Count how many triangles use each edge, and how thin the shape
+	// is at each vertex: its smallest height in the triangles it is in
+	type edgeKey struct{ a, b int }
+	uses := make(map[edgeKey]int)
+	var candidates []fringeEdge
+	thickness := make([]float32, count)
+	for i := range thickness {
+		thickness[i] = float32(math.Inf(1))
+	}
+	for t := 0; t+2 < len(indices); t += 3 {
+		tri := [3]int{ids[indices[t]], ids[indices[t+1]], ids[indices[t+2]]}
+		if tri[0] == tri[1] || tri[1] == tri[2] || tri[0] == tri[2] {
+			continue
+		}
+		for k := 0; k < 3; k++ {
+			a, b, c := tri[k], tri[(k+1)%3], tri[(k+2)%3]
+			pa, pb, pc := position(a), position(b), position(c)
+			if base := pb.Sub(pc).Length(); base > 0 {
+				height := abs32(pb.Sub(pa).Cross(pc.Sub(pa))) / base
+				thickness[a] = min(thickness[a], height)
+			}
+
+			key := edgeKey{min(a, b), max(a, b)}
+			uses[key]++
+			if uses[key] > 1 {
+				continue
+			}
+			n := Vector2{pa.Y - pb.Y, pb.X - pa.X}.Normalize()
+			if n.Dot(pc.Sub(pa)) > 0 {
+				n = n.Scale(-1)
+			}
+			candidates = append(candidates, fringeEdge{a, b, n})
+		}
+	}
+
+	// Sum the outline normals at each vertex
+	var edges []fringeEdge
+	normals := make([]Vector2, count)
+	edgeNormals := make([]Vector2, count) // normal of one outline edge at the vertex
+	for _, e := range candidates {
+		if uses[edgeKey{min(e.a, e.b), max(e.a, e.b)}] != 1 || e.normal == (Vector2{}) {
+			continue
+		}
+		edges = append(edges, e)
+		for _, id := range [2]int{e.a, e.b} {
+			if normals[id] == (Vector2{}) {
+				edgeNormals[id] = e.normal
+			}
+			normals[id] = normals[id].Add(e.normal)
+		}
+	}
+	if len(edges) == 0 {
+		return vertices, indices
+	}
+
+	// Miter the summed normal so both edges move by the same distance,
+	// then add faded copies out. Vertices go in id order so the mesh is
+	// the same on every run.
+	width := fringeWidth()
+	insets := make([]Vector2, count)
+	outer := make([]uint32, count)
+	for id := 0; id < count; id++ {
+		if ids[id] != id || edgeNormals[id] == (Vector2{}) {
+			continue
+		}
+		n := normals[id].Normalize()
+		scale := 1 / max(n.Dot(edgeNormals[id]), 0.25)
+
+		// Thin parts move in less, and never past half their thickness
+		h := thickness[id]
+		inset := min(width/2, max(0, (h-width)/2))
+		insets[id] = n.Scale(min(inset*scale, h/2))
+
+		p := position(id)
+		out := n.Scale(width / 2 * scale)
+		color := vertices[id*stride+2 : id*stride+6]
+		outer[id] = uint32(len(vertices) / stride)
+		vertices = append(vertices, p.X+out.X, p.Y+out.Y, color[0], color[1], color[2], 0)
+	}
+	for i := 0; i < count; i++ {
+		inset := insets[ids[i]]
+		vertices[i*stride] -= inset.X
+		vertices[i*stride+1] -= inset.Y
+	}
+
+	for _, e := range edges {
+		a, b := uint32(e.a), uint32(e.b)
+		indices = append(indices, a, b, outer[e.b], outer[e.b], outer[e.a], a)
+	}
+	return vertices, indices
+}
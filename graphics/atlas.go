@@ -0,0 +1,139 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/QOthman/Pixu/graphics/atlas"
+)
+
+// AtlasOptions control how PackAtlas places images, see the atlas package
+type AtlasOptions = atlas.Options
+
+// Atlas holds many images packed into a few textures, so drawing
+// them one after another doesn't switch textures.
+type Atlas struct {
+	Pages   []*Image
+	regions map[string]AtlasRegion
+}
+
+// AtlasRegion is one image inside an atlas page
+type AtlasRegion struct {
+	Image *Image    // the page texture
+	Src   Rectangle // area in the page, from the top-left
+}
+
+// Options returns DrawOptions that draw the region at (x, y) at its own size
+// Use them with DrawImageEx(region.Image, opts), changing size, tint or rotation as needed.
+func (r AtlasRegion) Options(x, y float32) DrawOptions {
+	return regionOptions(r.Image, r.Src, Rect(x, y, r.Src.Width, r.Src.Height), WHITE)
+}
+
+// Draw draws the region at (x, y) at its own size
+func (r AtlasRegion) Draw(x, y float32) {
+	DrawImageEx(r.Image, r.Options(x, y))
+}
+
+// PackAtlas packs images at runtime and uploads the pages
+func PackAtlas(images map[string]image.Image, opts AtlasOptions) (*Atlas, error) {
+	packed, err := atlas.Pack(images, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := &Atlas{regions: make(map[string]AtlasRegion, len(packed.Regions))}
+	for _, page := range packed.Pages {
+		a.Pages = append(a.Pages, NewImageFromImage(page))
+	}
+	a.addRegions(packed.Regions)
+	return a, nil
+}
+
+// PackAtlasFiles loads image files and packs them
+// Regions are named after the file names without extension.
+func PackAtlasFiles(paths []string, opts AtlasOptions) (*Atlas, error) {
+	images := make(map[string]image.Image, len(paths))
+	for _, path := range paths {
+		img, err := decodeImageFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, ok := images[name]; ok {
+			return nil, fmt.Errorf("atlas: two images named %q", name)
+		}
+		images[name] = img
+	}
+	return PackAtlas(images, opts)
+}
+
+// LoadAtlas loads an atlas made by cmd/pixu-atlas from its JSON manifest
+func LoadAtlas(manifestPath string) (*Atlas, error) {
+	manifest, err := atlas.LoadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	a := &Atlas{regions: make(map[string]AtlasRegion, len(manifest.Regions))}
+	dir := filepath.Dir(manifestPath)
+	for _, page := range manifest.Pages {
+		img, err := LoadImage(filepath.Join(dir, page))
+		if err != nil {
+			a.Delete()
+			return nil, err
+		}
+		a.Pages = append(a.Pages, img)
+	}
+	a.addRegions(manifest.Regions)
+	return a, nil
+}
+
+func (a *Atlas) addRegions(regions []atlas.Region) {
+	for _, r := range regions {
+		a.regions[r.Name] = AtlasRegion{
+			Image: a.Pages[r.Page],
+			Src:   Rect(float32(r.X), float32(r.Y), float32(r.Width), float32(r.Height)),
+		}
+	}
+}
+
+// Region returns a named region
+func (a *Atlas) Region(name string) (AtlasRegion, bool) {
+	r, ok := a.regions[name]
+	return r, ok
+}
+
+// Names returns the region names in order
+func (a *Atlas) Names() []string {
+	names := make([]string, 0, len(a.regions))
+	for name := range a.regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Draw draws a named region at (x, y), nothing if there is no such region
+func (a *Atlas) Draw(name string, x, y float32) {
+	if r, ok := a.regions[name]; ok {
+		r.Draw(x, y)
+	}
+}
+
+// DrawEx draws a named region with options, the source rect comes from the region
+// Width and Height default to the region size.
+func (a *Atlas) DrawEx(name string, opts DrawOptions) {
+	if r, ok := a.regions[name]; ok {
+		drawRegion(r.Image, r.Src, opts)
+	}
+}
+
+// Delete frees the page textures
+func (a *Atlas) Delete() {
+	for _, page := range a.Pages {
+		page.Delete()
+	}
+	a.Pages = nil
+	a.regions = map[string]AtlasRegion{}
+}
@@ -0,0 +1,222 @@
+// Package atlas packs many small images into a few large pages.
+//
+// It has no OpenGL dependency, so tools like cmd/pixu-atlas can use it
+// to build atlases ahead of time. graphics.LoadAtlas loads the result,
+// graphics.PackAtlas does the packing at startup instead.
+//
+// Images are placed with the MaxRects algorithm (best short side fit),
+// the biggest ones first, so the same input always gives the same atlas.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Options control how images are packed.
+type Options struct {
+	MaxWidth   int  // page width limit, 2048 if 0
+	MaxHeight  int  // page height limit, 2048 if 0
+	Padding    int  // empty pixels between images
+	Extrude    int  // edge pixels repeated around each image, stops neighbours bleeding in when filtered
+	PowerOfTwo bool // round page sizes up to powers of two, the limits are rounded down
+}
+
+// Region is where an image ended up.
+// X and Y are in page pixels from the top-left corner.
+type Region struct {
+	Name   string `json:"name"`
+	Page   int    `json:"page"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Atlas is the packed pages and the regions in them.
+type Atlas struct {
+	Pages   []*image.RGBA
+	Regions []Region
+}
+
+// Manifest is the JSON file saved next to the page PNGs.
+// Page file names are relative to the manifest.
+type Manifest struct {
+	Pages   []string `json:"pages"`
+	Regions []Region `json:"regions"`
+}
+
+// Pack places the images on as few pages as it can.
+// It fails if an image can't fit on a page at all.
+func Pack(images map[string]image.Image, opts Options) (*Atlas, error) {
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = 2048
+	}
+	if opts.MaxHeight <= 0 {
+		opts.MaxHeight = 2048
+	}
+	if opts.PowerOfTwo {
+		// Rounded up pages must still fit the limits
+		opts.MaxWidth, opts.MaxHeight = prevPowerOfTwo(opts.MaxWidth), prevPowerOfTwo(opts.MaxHeight)
+	}
+
+	// Biggest first packs tighter, names break ties so output is stable
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := images[names[i]].Bounds(), images[names[j]].Bounds()
+		sa, sb := max(a.Dx(), a.Dy()), max(b.Dx(), b.Dy())
+		if sa != sb {
+			return sa > sb
+		}
+		if a.Dx()*a.Dy() != b.Dx()*b.Dy() {
+			return a.Dx()*a.Dy() > b.Dx()*b.Dy()
+		}
+		return names[i] < names[j]
+	})
+
+	var bins []*maxRects
+	regions := make([]Region, 0, len(names))
+	for _, name := range names {
+		bounds := images[name].Bounds()
+		// Room for the image, its extruded border and the gap after it
+		w := bounds.Dx() + 2*opts.Extrude + opts.Padding
+		h := bounds.Dy() + 2*opts.Extrude + opts.Padding
+
+		page, spot, ok := -1, rect{}, false
+		for i, bin := range bins {
+			if spot, ok = bin.insert(w, h); ok {
+				page = i
+				break
+			}
+		}
+		if !ok {
+			bin := newMaxRects(opts.MaxWidth+opts.Padding, opts.MaxHeight+opts.Padding)
+			if spot, ok = bin.insert(w, h); !ok {
+				return nil, fmt.Errorf("atlas: %s (%dx%d) is larger than a page (%dx%d)",
+					name, bounds.Dx(), bounds.Dy(), opts.MaxWidth, opts.MaxHeight)
+			}
+			bins = append(bins, bin)
+			page = len(bins) - 1
+		}
+		regions = append(regions, Region{
+			Name:   name,
+			Page:   page,
+			X:      spot.x + opts.Extrude,
+			Y:      spot.y + opts.Extrude,
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+		})
+	}
+
+	// Pages are cropped to what they use
+	pages := make([]*image.RGBA, len(bins))
+	for i, bin := range bins {
+		w, h := bin.usedSize(opts.Padding)
+		if opts.PowerOfTwo {
+			w, h = nextPowerOfTwo(w), nextPowerOfTwo(h)
+		}
+		pages[i] = image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	}
+	for _, r := range regions {
+		blit(pages[r.Page], images[r.Name], r.X, r.Y, opts.Extrude)
+	}
+
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Name < regions[j].Name })
+	return &Atlas{Pages: pages, Regions: regions}, nil
+}
+
+// Save writes the pages as <name>_<page>.png and the manifest as <name>.json in dir.
+func (a *Atlas) Save(dir, name string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	manifest := Manifest{Regions: a.Regions}
+	for i, page := range a.Pages {
+		file := fmt.Sprintf("%s_%d.png", name, i)
+		if err := savePNG(filepath.Join(dir, file), page); err != nil {
+			return err
+		}
+		manifest.Pages = append(manifest.Pages, file)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".json"), append(data, '\n'), 0o644)
+}
+
+// LoadManifest reads a manifest written by Save.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("atlas: %s: %w", path, err)
+	}
+	for _, r := range m.Regions {
+		if r.Page < 0 || r.Page >= len(m.Pages) {
+			return nil, fmt.Errorf("atlas: %s: region %q is on missing page %d", path, r.Name, r.Page)
+		}
+	}
+	return &m, nil
+}
+
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Copy src to (x, y) and repeat its edge pixels extrude times around it
+func blit(dst *image.RGBA, src image.Image, x, y, extrude int) {
+	b := src.Bounds()
+	draw.Draw(dst, image.Rect(x, y, x+b.Dx(), y+b.Dy()), src, b.Min, draw.Src)
+	if b.Empty() {
+		return
+	}
+	for e := 1; e <= extrude; e++ {
+		for i := -e; i < b.Dx()+e; i++ {
+			sx := min(max(i, 0), b.Dx()-1)
+			dst.Set(x+i, y-e, dst.At(x+sx, y))
+			dst.Set(x+i, y+b.Dy()-1+e, dst.At(x+sx, y+b.Dy()-1))
+		}
+		for j := -e; j < b.Dy()+e; j++ {
+			sy := min(max(j, 0), b.Dy()-1)
+			dst.Set(x-e, y+j, dst.At(x, y+sy))
+			dst.Set(x+b.Dx()-1+e, y+j, dst.At(x+b.Dx()-1, y+sy))
+		}
+	}
+}
+
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p *= 2
+	}
+	return p
+}
+
+func prevPowerOfTwo(v int) int {
+	p := 1
+	for p*2 <= v {
+		p *= 2
+	}
+	return p
+}
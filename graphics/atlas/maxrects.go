@@ -0,0 +1,95 @@
+package atlas
+
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(o rect) bool {
+	return o.x >= r.x && o.y >= r.y && o.x+o.w <= r.x+r.w && o.y+o.h <= r.y+r.h
+}
+
+func (r rect) intersects(o rect) bool {
+	return o.x < r.x+r.w && o.x+o.w > r.x && o.y < r.y+r.h && o.y+o.h > r.y
+}
+
+// maxRects is one page being filled
+// free holds every largest empty rectangle, they may overlap.
+type maxRects struct {
+	width, height int
+	free          []rect
+	used          []rect
+}
+
+func newMaxRects(width, height int) *maxRects {
+	return &maxRects{width: width, height: height, free: []rect{{0, 0, width, height}}}
+}
+
+// Place a w x h rectangle where it leaves the smallest leftover on its shorter side
+func (m *maxRects) insert(w, h int) (rect, bool) {
+	best, found := rect{}, false
+	bestShort, bestLong := 0, 0
+	for _, f := range m.free {
+		if f.w < w || f.h < h {
+			continue
+		}
+		short, long := min(f.w-w, f.h-h), max(f.w-w, f.h-h)
+		if !found || short < bestShort || (short == bestShort && long < bestLong) {
+			best, found = rect{f.x, f.y, w, h}, true
+			bestShort, bestLong = short, long
+		}
+	}
+	if !found {
+		return rect{}, false
+	}
+	m.place(best)
+	return best, true
+}
+
+// Cut the placed rectangle out of every free one it overlaps
+func (m *maxRects) place(r rect) {
+	var next []rect
+	for _, f := range m.free {
+		if !f.intersects(r) {
+			next = append(next, f)
+			continue
+		}
+		if r.x > f.x {
+			next = append(next, rect{f.x, f.y, r.x - f.x, f.h})
+		}
+		if r.x+r.w < f.x+f.w {
+			next = append(next, rect{r.x + r.w, f.y, f.x + f.w - r.x - r.w, f.h})
+		}
+		if r.y > f.y {
+			next = append(next, rect{f.x, f.y, f.w, r.y - f.y})
+		}
+		if r.y+r.h < f.y+f.h {
+			next = append(next, rect{f.x, r.y + r.h, f.w, f.y + f.h - r.y - r.h})
+		}
+	}
+
+	// Drop free rectangles inside others
+	m.free = m.free[:0]
+	for i, a := range next {
+		inside := false
+		for j, b := range next {
+			if i != j && b.contains(a) && (a != b || j < i) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			m.free = append(m.free, a)
+		}
+	}
+	m.used = append(m.used, r)
+}
+
+// Size the used rectangles cover, without the padding after the last ones
+func (m *maxRects) usedSize(padding int) (int, int) {
+	w, h := 0, 0
+	for _, r := range m.used {
+		w = max(w, r.x+r.w-padding)
+		h = max(h, r.y+r.h-padding)
+	}
+	return w, h
+}
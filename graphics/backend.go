@@ -0,0 +1,104 @@
+package graphics
+
+import (
+	"image"
+)
+
+// Primitive is the kind of geometry in a DrawCommand.
+type Primitive int
+
+const (
+	Triangles Primitive = iota // every 3 indices form a triangle
+	Lines                      // every 2 indices form a line
+)
+
+// VertexFormat tells a Backend how to read DrawCommand.Vertices.
+// Positions are in world coordinates, DrawCommand.Transform maps them
+// to normalized device coordinates (-1..1, Y up).
+type VertexFormat int
+
+const (
+	ShapeVertex   VertexFormat = iota // x, y, r, g, b, a
+	TextureVertex                     // x, y, u, v, r, g, b, a
+)
+
+// Stride returns the number of floats per vertex
+func (f VertexFormat) Stride() int {
+	if f == TextureVertex {
+		return 8
+	}
+	return 6
+}
+
+// DrawCommand is one flushed batch of indexed geometry.
+type DrawCommand struct {
+	Format    VertexFormat
+	Primitive Primitive
+	Texture   uint32 // 0 for untextured shapes
+	Blend     BlendMode
+	Transform Matrix          // world to normalized device coordinates
+	Shader    uint32          // custom shader, 0 for the built-in one (always TextureVertex)
+	Scissor   image.Rectangle // pixels of the bound surface that can change, rows top-down
+	Clipped   bool            // Scissor is in use
+	Stencil   StencilMode
+	Vertices  []float32
+	Indices   []uint32
+}
+
+// Backend is the renderer behind all the Draw* functions.
+// The OpenGL backend draws to the window, the software backend
+// rasterizes into an image.RGBA without needing a GPU or a display.
+type Backend interface {
+	// Resize is called when the window changes size
+	Resize(width, height int)
+	// Clear fills the bound surface with a color
+	Clear(color Color)
+	// NewTexture uploads RGBA pixels (rows bottom-up) and returns its id
+	NewTexture(width, height int, pixels []byte) uint32
+	// UpdateTexture replaces a width x height area of a texture at (x, y),
+	// counted from the bottom-left like its rows. The area must fit inside.
+	UpdateTexture(id uint32, x, y, width, height int, pixels []byte)
+	// DeleteTexture frees a texture created by NewTexture
+	DeleteTexture(id uint32)
+	// Draw renders one batch of geometry
+	Draw(cmd DrawCommand)
+	// ReadPixels returns a copy of the window surface, rows top-down
+	ReadPixels() *image.RGBA
+
+	// NewRenderTarget creates an off-screen surface and the texture holding its pixels
+	NewRenderTarget(width, height int) (target, texture uint32, err error)
+	// DeleteRenderTarget frees a render target and its texture
+	DeleteRenderTarget(target uint32)
+	// BindRenderTarget makes later draws and clears go to a target,
+	// or back to the window when target is 0
+	BindRenderTarget(target uint32, width, height int)
+
+	// NewShader compiles a custom shader taking TextureVertex input
+	NewShader(vertexSource, fragmentSource string) (uint32, error)
+	// DeleteShader frees a custom shader
+	DeleteShader(shader uint32)
+	// SetUniform sets a float, vec2, vec3, vec4 or mat3 uniform by its length
+	SetUniform(shader uint32, name string, values []float32)
+	// SetUniformTexture binds a texture to a sampler uniform
+	SetUniformTexture(shader uint32, name string, texture uint32)
+
+	// ClearMask empties the stencil mask of the bound surface
+	ClearMask()
+}
+
+// Pixels below this alpha are left out of a mask, by every backend
+const maskAlphaCutoff = 0.01
+
+// Backend in use, set by Init or InitHeadless
+var backend Backend
+
+// GetBackend returns the backend the graphics system is drawing with.
+func GetBackend() Backend {
+	return backend
+}
+
+// Screenshot flushes pending draws and returns the current frame as an image.
+func Screenshot() *image.RGBA {
+	flushBatch()
+	return backend.ReadPixels()
+}
@@ -0,0 +1,386 @@
+//go:build !pixu_headless
+
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// pipeline groups a shader program with the buffers that feed it
+type pipeline struct {
+	program       uint32
+	vao, vbo, ebo uint32
+	transform     int32 // uTransform uniform location
+	alphaCutoff   int32 // uAlphaCutoff uniform location
+}
+
+// glShader is a custom program drawn with the texture pipeline buffers
+type glShader struct {
+	transform int32
+	locations map[string]int32
+	samplers  map[string]*glSampler
+}
+
+// glSampler is an extra texture bound for a custom shader
+type glSampler struct {
+	unit    int32
+	texture uint32
+}
+
+// glTarget is a framebuffer with its color texture and stencil buffer
+type glTarget struct {
+	texture       uint32
+	stencil       uint32 // depth/stencil renderbuffer
+	width, height int
+}
+
+// OpenGL enums for the blend factors
+var glBlendFactors = [...]uint32{
+	factorZero:             gl.ZERO,
+	factorOne:              gl.ONE,
+	factorSrcAlpha:         gl.SRC_ALPHA,
+	factorOneMinusSrcAlpha: gl.ONE_MINUS_SRC_ALPHA,
+	factorSrcColor:         gl.SRC_COLOR,
+	factorOneMinusSrcColor: gl.ONE_MINUS_SRC_COLOR,
+	factorDstColor:         gl.DST_COLOR,
+	factorOneMinusDstColor: gl.ONE_MINUS_DST_COLOR,
+}
+
+// glBackend renders with OpenGL 3.3 core into the current context
+type glBackend struct {
+	shape, texture pipeline
+	width, height  int
+
+	targets map[uint32]*glTarget // by framebuffer id
+	bound   uint32               // framebuffer drawn to, 0 is the window
+	shaders map[uint32]*glShader
+}
+
+// Create the OpenGL backend. A context must be current.
+func newGLBackend(width, height int) (*glBackend, error) {
+	if err := gl.Init(); err != nil {
+		return nil, err
+	}
+	b := &glBackend{
+		width:   width,
+		height:  height,
+		targets: make(map[uint32]*glTarget),
+		shaders: make(map[uint32]*glShader),
+	}
+	gl.Viewport(0, 0, int32(width), int32(height))
+	gl.Enable(gl.MULTISAMPLE) // only matters if SetMSAA asked for samples
+	if err := setupShaders(&b.shape); err != nil {
+		return nil, err
+	}
+	setupBuffers(&b.shape)
+	if err := setupTextureShaders(&b.texture); err != nil {
+		return nil, err
+	}
+	setupTextureBuffers(&b.texture)
+	return b, nil
+}
+
+func (b *glBackend) Resize(width, height int) {
+	b.width, b.height = width, height
+	if b.bound == 0 {
+		gl.Viewport(0, 0, int32(width), int32(height))
+	}
+	// A render target keeps its own viewport, the window one
+	// is set again when it is unbound
+}
+
+func (b *glBackend) Clear(color Color) {
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.ClearColor(color.R, color.G, color.B, color.A)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+}
+
+func (b *glBackend) ClearMask() {
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.StencilMask(0xFF)
+	gl.ClearStencil(0)
+	gl.Clear(gl.STENCIL_BUFFER_BIT)
+}
+
+// pixels may be nil to allocate an empty texture
+func (b *glBackend) NewTexture(width, height int, pixels []byte) uint32 {
+	var textureID uint32
+	gl.GenTextures(1, &textureID)
+	gl.BindTexture(gl.TEXTURE_2D, textureID)
+
+	// Texture parameters
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+	// Upload texture data
+	var data unsafe.Pointer
+	if len(pixels) > 0 {
+		data = gl.Ptr(pixels)
+	}
+	gl.TexImage2D(
+		gl.TEXTURE_2D, 0, gl.RGBA,
+		int32(width), int32(height), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE,
+		data,
+	)
+	return textureID
+}
+
+func (b *glBackend) UpdateTexture(id uint32, x, y, width, height int, pixels []byte) {
+	if len(pixels) < width*height*4 {
+		return
+	}
+	gl.BindTexture(gl.TEXTURE_2D, id)
+	gl.TexSubImage2D(
+		gl.TEXTURE_2D, 0,
+		int32(x), int32(y), int32(width), int32(height),
+		gl.RGBA, gl.UNSIGNED_BYTE,
+		gl.Ptr(pixels),
+	)
+}
+
+func (b *glBackend) DeleteTexture(id uint32) {
+	gl.DeleteTextures(1, &id)
+}
+
+func (b *glBackend) Draw(cmd DrawCommand) {
+	pipe := &b.shape
+	if cmd.Format == TextureVertex {
+		pipe = &b.texture
+	}
+	program, transform := pipe.program, pipe.transform
+	shader := b.shaders[cmd.Shader]
+	if shader != nil {
+		program, transform = cmd.Shader, shader.transform
+		for _, sampler := range shader.samplers {
+			gl.ActiveTexture(gl.TEXTURE0 + uint32(sampler.unit))
+			gl.BindTexture(gl.TEXTURE_2D, sampler.texture)
+		}
+	}
+
+	if cmd.Texture != 0 {
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, cmd.Texture)
+	}
+
+	gl.BindVertexArray(pipe.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, pipe.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, int(unsafe.Sizeof(cmd.Vertices[0]))*len(cmd.Vertices), gl.Ptr(cmd.Vertices), gl.DYNAMIC_DRAW)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, pipe.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(unsafe.Sizeof(cmd.Indices[0]))*len(cmd.Indices), gl.Ptr(cmd.Indices), gl.DYNAMIC_DRAW)
+
+	if cmd.Blend == BlendReplace {
+		gl.Disable(gl.BLEND)
+	} else {
+		f := blendFuncs[cmd.Blend]
+		gl.Enable(gl.BLEND)
+		gl.BlendFuncSeparate(glBlendFactors[f.srcRGB], glBlendFactors[f.dstRGB], glBlendFactors[f.srcAlpha], glBlendFactors[f.dstAlpha])
+	}
+
+	b.setClip(cmd)
+
+	mode := uint32(gl.TRIANGLES)
+	if cmd.Primitive == Lines {
+		mode = gl.LINES
+	}
+	gl.UseProgram(program)
+	gl.UniformMatrix3fv(transform, 1, false, &cmd.Transform[0])
+	if shader == nil {
+		cutoff := float32(-1)
+		if cmd.Stencil == StencilWrite {
+			cutoff = maskAlphaCutoff
+		}
+		gl.Uniform1f(pipe.alphaCutoff, cutoff)
+	}
+	gl.DrawElements(mode, int32(len(cmd.Indices)), gl.UNSIGNED_INT, nil)
+}
+
+// Set up the scissor and stencil tests for a command
+func (b *glBackend) setClip(cmd DrawCommand) {
+	if cmd.Clipped {
+		// Scissor rows count from the bottom of the surface
+		_, height := b.surfaceSize()
+		r := cmd.Scissor
+		gl.Enable(gl.SCISSOR_TEST)
+		gl.Scissor(int32(r.Min.X), int32(height-r.Max.Y), int32(r.Dx()), int32(r.Dy()))
+	} else {
+		gl.Disable(gl.SCISSOR_TEST)
+	}
+
+	switch cmd.Stencil {
+	case StencilOff:
+		gl.Disable(gl.STENCIL_TEST)
+		gl.ColorMask(true, true, true, true)
+		return
+	case StencilWrite:
+		gl.StencilFunc(gl.ALWAYS, 1, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+		gl.StencilMask(0xFF)
+		gl.ColorMask(false, false, false, false)
+	case StencilInside:
+		gl.StencilFunc(gl.EQUAL, 1, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+		gl.StencilMask(0x00)
+		gl.ColorMask(true, true, true, true)
+	case StencilOutside:
+		gl.StencilFunc(gl.NOTEQUAL, 1, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+		gl.StencilMask(0x00)
+		gl.ColorMask(true, true, true, true)
+	}
+	gl.Enable(gl.STENCIL_TEST)
+}
+
+// Size of the framebuffer being drawn to
+func (b *glBackend) surfaceSize() (int, int) {
+	if target, ok := b.targets[b.bound]; ok {
+		return target.width, target.height
+	}
+	return b.width, b.height
+}
+
+func (b *glBackend) ReadPixels() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, b.width, b.height))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(b.width), int32(b.height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, b.bound)
+
+	// OpenGL rows start at the bottom, flip them
+	row := make([]byte, img.Stride)
+	for top, bottom := 0, b.height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		copy(row, img.Pix[top*img.Stride:])
+		copy(img.Pix[top*img.Stride:(top+1)*img.Stride], img.Pix[bottom*img.Stride:])
+		copy(img.Pix[bottom*img.Stride:(bottom+1)*img.Stride], row)
+	}
+	return img
+}
+
+func (b *glBackend) NewRenderTarget(width, height int) (uint32, uint32, error) {
+	textureID := b.NewTexture(width, height, nil)
+
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, textureID, 0)
+
+	// Stencil buffer for masks
+	var rbo uint32
+	gl.GenRenderbuffers(1, &rbo)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, rbo)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, rbo)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, b.bound)
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		gl.DeleteFramebuffers(1, &fbo)
+		gl.DeleteRenderbuffers(1, &rbo)
+		gl.DeleteTextures(1, &textureID)
+		return 0, 0, fmt.Errorf("render target incomplete: status 0x%x", status)
+	}
+	b.targets[fbo] = &glTarget{texture: textureID, stencil: rbo, width: width, height: height}
+	return fbo, textureID, nil
+}
+
+func (b *glBackend) DeleteRenderTarget(target uint32) {
+	t, ok := b.targets[target]
+	if !ok {
+		return
+	}
+	if b.bound == target {
+		b.BindRenderTarget(0, b.width, b.height)
+	}
+	gl.DeleteFramebuffers(1, &target)
+	gl.DeleteRenderbuffers(1, &t.stencil)
+	gl.DeleteTextures(1, &t.texture)
+	delete(b.targets, target)
+}
+
+func (b *glBackend) BindRenderTarget(target uint32, width, height int) {
+	b.bound = target
+	gl.BindFramebuffer(gl.FRAMEBUFFER, target)
+	if target == 0 {
+		width, height = b.width, b.height
+	}
+	gl.Viewport(0, 0, int32(width), int32(height))
+}
+
+func (b *glBackend) NewShader(vertexSource, fragmentSource string) (uint32, error) {
+	program, err := linkProgram(vertexSource, fragmentSource)
+	if err != nil {
+		return 0, err
+	}
+	shader := &glShader{
+		locations: make(map[string]int32),
+		samplers:  make(map[string]*glSampler),
+	}
+	b.shaders[program] = shader
+	shader.transform = b.uniformLocation(program, "uTransform")
+
+	gl.UseProgram(program)
+	gl.Uniform1i(b.uniformLocation(program, "ourTexture"), 0) // Texture unit 0
+	return program, nil
+}
+
+func (b *glBackend) DeleteShader(shader uint32) {
+	if _, ok := b.shaders[shader]; !ok {
+		return
+	}
+	gl.DeleteProgram(shader)
+	delete(b.shaders, shader)
+}
+
+func (b *glBackend) SetUniform(shader uint32, name string, values []float32) {
+	if _, ok := b.shaders[shader]; !ok {
+		return
+	}
+	location := b.uniformLocation(shader, name)
+	gl.UseProgram(shader)
+	switch len(values) {
+	case 1:
+		gl.Uniform1f(location, values[0])
+	case 2:
+		gl.Uniform2f(location, values[0], values[1])
+	case 3:
+		gl.Uniform3f(location, values[0], values[1], values[2])
+	case 4:
+		gl.Uniform4f(location, values[0], values[1], values[2], values[3])
+	case 9:
+		gl.UniformMatrix3fv(location, 1, false, &values[0])
+	}
+}
+
+func (b *glBackend) SetUniformTexture(shader uint32, name string, texture uint32) {
+	s, ok := b.shaders[shader]
+	if !ok {
+		return
+	}
+	sampler, ok := s.samplers[name]
+	if !ok {
+		// Unit 0 is the image being drawn, extra textures go after it
+		sampler = &glSampler{unit: int32(len(s.samplers) + 1)}
+		s.samplers[name] = sampler
+		gl.UseProgram(shader)
+		gl.Uniform1i(b.uniformLocation(shader, name), sampler.unit)
+	}
+	sampler.texture = texture
+}
+
+// Look up a uniform location, caching it per shader
+func (b *glBackend) uniformLocation(shader uint32, name string) int32 {
+	s := b.shaders[shader]
+	if location, ok := s.locations[name]; ok {
+		return location
+	}
+	location := gl.GetUniformLocation(shader, gl.Str(name+"\x00"))
+	s.locations[name] = location
+	return location
+}
@@ -0,0 +1,409 @@
+package graphics
+
+import (
+	"image"
+	"math"
+)
+
+// softTexture keeps texture pixels the way they were uploaded (rows bottom-up)
+type softTexture struct {
+	width, height int
+	pix           []byte
+}
+
+// SoftwareBackend rasterizes draw commands into an image.RGBA in pure Go.
+// It needs no GPU or window, which makes it usable in CI and in tools.
+// With the pixu_headless build tag it is the only backend compiled in.
+type SoftwareBackend struct {
+	screen   *image.RGBA
+	target   *image.RGBA // screen or the pixels of a render target
+	flipY    bool        // render targets store rows bottom-up like textures
+	textures map[uint32]*softTexture
+	targets  map[uint32]uint32 // render target id -> texture id
+	bound    uint32
+	nextID   uint32
+
+	masks   map[uint32][]uint8 // stencil per surface, by render target id
+	clip    image.Rectangle    // pixels the current command may touch
+	stencil StencilMode        // mask use of the current command
+	mask    []uint8            // mask of the bound surface, same layout as target
+}
+
+// NewSoftwareBackend creates a software backend with a surface of the given size.
+func NewSoftwareBackend(width, height int) *SoftwareBackend {
+	screen := image.NewRGBA(image.Rect(0, 0, width, height))
+	return &SoftwareBackend{
+		screen:   screen,
+		target:   screen,
+		textures: make(map[uint32]*softTexture),
+		targets:  make(map[uint32]uint32),
+		masks:    make(map[uint32][]uint8),
+		nextID:   1,
+	}
+}
+
+// Image returns the window surface the backend draws into.
+func (b *SoftwareBackend) Image() *image.RGBA {
+	return b.screen
+}
+
+func (b *SoftwareBackend) Resize(width, height int) {
+	b.screen = image.NewRGBA(image.Rect(0, 0, width, height))
+	delete(b.masks, 0)
+	if b.bound == 0 {
+		b.target = b.screen
+	}
+}
+
+func (b *SoftwareBackend) Clear(color Color) {
+	r, g, bl, a := toByte(color.R), toByte(color.G), toByte(color.B), toByte(color.A)
+	pix := b.target.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i], pix[i+1], pix[i+2], pix[i+3] = r, g, bl, a
+	}
+}
+
+func (b *SoftwareBackend) ClearMask() {
+	clear(b.masks[b.bound])
+}
+
+// Mask of the bound surface, created on first use
+func (b *SoftwareBackend) boundMask() []uint8 {
+	mask := b.masks[b.bound]
+	if size := b.target.Rect.Dx() * b.target.Rect.Dy(); len(mask) != size {
+		mask = make([]uint8, size)
+		b.masks[b.bound] = mask
+	}
+	return mask
+}
+
+func (b *SoftwareBackend) NewTexture(width, height int, pixels []byte) uint32 {
+	id := b.nextID
+	b.nextID++
+	pix := make([]byte, width*height*4)
+	copy(pix, pixels)
+	b.textures[id] = &softTexture{width: width, height: height, pix: pix}
+	return id
+}
+
+func (b *SoftwareBackend) UpdateTexture(id uint32, x, y, width, height int, pixels []byte) {
+	tex := b.textures[id]
+	if tex == nil || x < 0 || y < 0 || x+width > tex.width || y+height > tex.height {
+		return
+	}
+	row := width * 4
+	for r := 0; r < height && (r+1)*row <= len(pixels); r++ {
+		copy(tex.pix[((y+r)*tex.width+x)*4:], pixels[r*row:(r+1)*row])
+	}
+}
+
+func (b *SoftwareBackend) DeleteTexture(id uint32) {
+	delete(b.textures, id)
+}
+
+func (b *SoftwareBackend) ReadPixels() *image.RGBA {
+	img := image.NewRGBA(b.screen.Rect)
+	copy(img.Pix, b.screen.Pix)
+	return img
+}
+
+func (b *SoftwareBackend) NewRenderTarget(width, height int) (uint32, uint32, error) {
+	textureID := b.NewTexture(width, height, nil)
+	id := b.nextID
+	b.nextID++
+	b.targets[id] = textureID
+	return id, textureID, nil
+}
+
+func (b *SoftwareBackend) DeleteRenderTarget(target uint32) {
+	textureID, ok := b.targets[target]
+	if !ok {
+		return
+	}
+	if b.bound == target {
+		b.BindRenderTarget(0, 0, 0)
+	}
+	b.DeleteTexture(textureID)
+	delete(b.targets, target)
+	delete(b.masks, target)
+}
+
+func (b *SoftwareBackend) BindRenderTarget(target uint32, width, height int) {
+	tex := b.textures[b.targets[target]]
+	if target == 0 || tex == nil {
+		b.bound, b.target, b.flipY = 0, b.screen, false
+		return
+	}
+	// Draw straight into the texture pixels so the result can be sampled
+	b.bound, b.flipY = target, true
+	b.target = &image.RGBA{
+		Pix:    tex.pix,
+		Stride: tex.width * 4,
+		Rect:   image.Rect(0, 0, tex.width, tex.height),
+	}
+}
+
+// Custom shaders can't run on the CPU, they are accepted and
+// everything is drawn with the built-in shading instead.
+func (b *SoftwareBackend) NewShader(vertexSource, fragmentSource string) (uint32, error) {
+	id := b.nextID
+	b.nextID++
+	return id, nil
+}
+
+func (b *SoftwareBackend) DeleteShader(shader uint32) {}
+
+func (b *SoftwareBackend) SetUniform(shader uint32, name string, values []float32) {}
+
+func (b *SoftwareBackend) SetUniformTexture(shader uint32, name string, texture uint32) {}
+
+// softVertex is a vertex converted to pixel space
+type softVertex struct {
+	x, y       float32
+	u, v       float32
+	r, g, b, a float32
+}
+
+func (b *SoftwareBackend) Draw(cmd DrawCommand) {
+	stride := cmd.Format.Stride()
+	width := float32(b.target.Rect.Dx())
+	height := float32(b.target.Rect.Dy())
+
+	verts := make([]softVertex, len(cmd.Vertices)/stride)
+	for i := range verts {
+		v := cmd.Vertices[i*stride : (i+1)*stride]
+		x, y := cmd.Transform.Apply(v[0], v[1])
+		if b.flipY {
+			y = -y
+		}
+		sv := softVertex{
+			x: (x + 1) / 2 * width,
+			y: (1 - y) / 2 * height,
+		}
+		if cmd.Format == TextureVertex {
+			sv.u, sv.v = v[2], v[3]
+			sv.r, sv.g, sv.b, sv.a = v[4], v[5], v[6], v[7]
+		} else {
+			sv.r, sv.g, sv.b, sv.a = v[2], v[3], v[4], v[5]
+		}
+		verts[i] = sv
+	}
+
+	b.clip = b.target.Rect
+	if cmd.Clipped {
+		scissor := cmd.Scissor
+		if b.flipY {
+			h := b.target.Rect.Dy()
+			scissor.Min.Y, scissor.Max.Y = h-cmd.Scissor.Max.Y, h-cmd.Scissor.Min.Y
+		}
+		b.clip = b.clip.Intersect(scissor)
+	}
+	b.stencil, b.mask = cmd.Stencil, nil
+	if cmd.Stencil != StencilOff {
+		b.mask = b.boundMask()
+	}
+
+	tex := b.textures[cmd.Texture]
+	if cmd.Primitive == Lines {
+		for i := 0; i+1 < len(cmd.Indices); i += 2 {
+			b.drawLine(verts[cmd.Indices[i]], verts[cmd.Indices[i+1]], tex, blendFuncs[cmd.Blend])
+		}
+		return
+	}
+	for i := 0; i+2 < len(cmd.Indices); i += 3 {
+		b.drawTriangle(verts[cmd.Indices[i]], verts[cmd.Indices[i+1]], verts[cmd.Indices[i+2]], tex, blendFuncs[cmd.Blend])
+	}
+}
+
+// Fill a triangle, sampling at pixel centers
+// Pixels exactly on an edge follow the top-left rule, so quads made of
+// two triangles don't blend their shared diagonal twice.
+func (b *SoftwareBackend) drawTriangle(v0, v1, v2 softVertex, tex *softTexture, blend blendFunc) {
+	area := edge(v0.x, v0.y, v1.x, v1.y, v2.x, v2.y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		v1, v2 = v2, v1
+		area = -area
+	}
+	bias0 := topLeftBias(v1, v2)
+	bias1 := topLeftBias(v2, v0)
+	bias2 := topLeftBias(v0, v1)
+
+	bounds := b.clip
+	minX := clampInt(int(floor32(min(v0.x, v1.x, v2.x))), bounds.Min.X, bounds.Max.X)
+	maxX := clampInt(int(ceil32(max(v0.x, v1.x, v2.x))), bounds.Min.X, bounds.Max.X)
+	minY := clampInt(int(floor32(min(v0.y, v1.y, v2.y))), bounds.Min.Y, bounds.Max.Y)
+	maxY := clampInt(int(ceil32(max(v0.y, v1.y, v2.y))), bounds.Min.Y, bounds.Max.Y)
+
+	for y := minY; y < maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x < maxX; x++ {
+			px := float32(x) + 0.5
+			e0 := edge(v1.x, v1.y, v2.x, v2.y, px, py)
+			e1 := edge(v2.x, v2.y, v0.x, v0.y, px, py)
+			e2 := edge(v0.x, v0.y, v1.x, v1.y, px, py)
+			if e0+bias0 <= 0 || e1+bias1 <= 0 || e2+bias2 <= 0 {
+				continue
+			}
+			b.shade(x, y, lerp3(v0, v1, v2, e0/area, e1/area, e2/area), tex, blend)
+		}
+	}
+}
+
+// Top and left edges own the pixels lying exactly on them
+func topLeftBias(a, b softVertex) float32 {
+	dx, dy := b.x-a.x, b.y-a.y
+	if dy < 0 || (dy == 0 && dx > 0) {
+		return math.SmallestNonzeroFloat32
+	}
+	return 0
+}
+
+// Draw a one pixel wide line from v0 to v1
+func (b *SoftwareBackend) drawLine(v0, v1 softVertex, tex *softTexture, blend blendFunc) {
+	dx, dy := v1.x-v0.x, v1.y-v0.y
+	steps := int(ceil32(max(abs32(dx), abs32(dy))))
+	if steps == 0 {
+		steps = 1
+	}
+	bounds := b.clip
+	for i := 0; i < steps; i++ {
+		t := (float32(i) + 0.5) / float32(steps)
+		x := int(floor32(v0.x + dx*t))
+		y := int(floor32(v0.y + dy*t))
+		if !(image.Point{x, y}).In(bounds) {
+			continue
+		}
+		b.shade(x, y, lerp3(v0, v1, v1, 1-t, t, 0), tex, blend)
+	}
+}
+
+// Compute the fragment color and write it to the target
+func (b *SoftwareBackend) shade(x, y int, v softVertex, tex *softTexture, blend blendFunc) {
+	r, g, bl, a := v.r, v.g, v.b, v.a
+	if tex != nil {
+		tr, tg, tb, ta := tex.sample(v.u, v.v)
+		r, g, bl, a = r*tr, g*tg, bl*tb, a*ta
+	}
+
+	switch b.stencil {
+	case StencilWrite:
+		if a > maskAlphaCutoff {
+			b.mask[y*b.target.Rect.Dx()+x] = 1
+		}
+		return
+	case StencilInside:
+		if b.mask[y*b.target.Rect.Dx()+x] == 0 {
+			return
+		}
+	case StencilOutside:
+		if b.mask[y*b.target.Rect.Dx()+x] != 0 {
+			return
+		}
+	}
+
+	i := b.target.PixOffset(x, y)
+	pix := b.target.Pix[i : i+4 : i+4]
+	src := [4]float32{r, g, bl, a}
+	dst := [4]float32{float32(pix[0]) / 255, float32(pix[1]) / 255, float32(pix[2]) / 255, float32(pix[3]) / 255}
+	for c := 0; c < 4; c++ {
+		srcFactor, dstFactor := blend.srcRGB, blend.dstRGB
+		if c == 3 {
+			srcFactor, dstFactor = blend.srcAlpha, blend.dstAlpha
+		}
+		out := src[c]*factorValue(srcFactor, src, dst, c) + dst[c]*factorValue(dstFactor, src, dst, c)
+		pix[c] = toByte(out)
+	}
+}
+
+// Value of a blend factor for channel c, as glBlendFuncSeparate computes it
+func factorValue(f blendFactor, src, dst [4]float32, c int) float32 {
+	switch f {
+	case factorOne:
+		return 1
+	case factorSrcAlpha:
+		return src[3]
+	case factorOneMinusSrcAlpha:
+		return 1 - src[3]
+	case factorSrcColor:
+		return src[c]
+	case factorOneMinusSrcColor:
+		return 1 - src[c]
+	case factorDstColor:
+		return dst[c]
+	case factorOneMinusDstColor:
+		return 1 - dst[c]
+	}
+	return 0
+}
+
+// Bilinear sample with clamp to edge, like GL_LINEAR + GL_CLAMP_TO_EDGE
+func (t *softTexture) sample(u, v float32) (float32, float32, float32, float32) {
+	fx := u*float32(t.width) - 0.5
+	fy := v*float32(t.height) - 0.5
+	x0, y0 := int(floor32(fx)), int(floor32(fy))
+	ax, ay := fx-float32(x0), fy-float32(y0)
+
+	var out [4]float32
+	for _, s := range [4]struct {
+		dx, dy int
+		w      float32
+	}{
+		{0, 0, (1 - ax) * (1 - ay)},
+		{1, 0, ax * (1 - ay)},
+		{0, 1, (1 - ax) * ay},
+		{1, 1, ax * ay},
+	} {
+		px := clampInt(x0+s.dx, 0, t.width-1)
+		py := clampInt(y0+s.dy, 0, t.height-1)
+		i := (py*t.width + px) * 4
+		for c := 0; c < 4; c++ {
+			out[c] += float32(t.pix[i+c]) / 255 * s.w
+		}
+	}
+	return out[0], out[1], out[2], out[3]
+}
+
+// Signed area of the parallelogram (a, b, c), positive when clockwise on screen
+func edge(ax, ay, bx, by, cx, cy float32) float32 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+// Interpolate vertex attributes with barycentric weights
+func lerp3(v0, v1, v2 softVertex, w0, w1, w2 float32) softVertex {
+	return softVertex{
+		u: v0.u*w0 + v1.u*w1 + v2.u*w2,
+		v: v0.v*w0 + v1.v*w1 + v2.v*w2,
+		r: v0.r*w0 + v1.r*w1 + v2.r*w2,
+		g: v0.g*w0 + v1.g*w1 + v2.g*w2,
+		b: v0.b*w0 + v1.b*w1 + v2.b*w2,
+		a: v0.a*w0 + v1.a*w1 + v2.a*w2,
+	}
+}
+
+func toByte(c float32) uint8 {
+	if c <= 0 {
+		return 0
+	}
+	if c >= 1 {
+		return 255
+	}
+	return uint8(c*255 + 0.5)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func floor32(v float32) float32 { return float32(math.Floor(float64(v))) }
+func ceil32(v float32) float32  { return float32(math.Ceil(float64(v))) }
+func abs32(v float32) float32   { return float32(math.Abs(float64(v))) }
+func sqrt32(v float32) float32  { return float32(math.Sqrt(float64(v))) }
@@ -0,0 +1,119 @@
+package graphics
+
+import (
+	"image"
+)
+
+// Maximum number of vertices kept in the batch before it is flushed
+const maxBatchVertices = 1 << 16
+
+// batchState is everything that forces a flush when it changes
+type batchState struct {
+	format    VertexFormat
+	texture   uint32
+	primitive Primitive
+	blend     BlendMode
+	transform Matrix
+	shader    uint32
+	scissor   image.Rectangle
+	clipped   bool // scissor is set
+	stencil   StencilMode
+}
+
+// renderBatch accumulates indexed geometry that shares one state
+type renderBatch struct {
+	state    batchState
+	vertices []float32
+	indices  []uint32
+}
+
+// RenderStats holds the batching counters for one frame.
+type RenderStats struct {
+	DrawCalls    int // draw calls sent to the backend
+	Vertices     int // vertices submitted
+	StateFlushes int // draw calls forced by a texture, blend, transform... change
+	FullFlushes  int // draw calls forced by the batch being full
+}
+
+var (
+	batch      renderBatch
+	frameStats RenderStats
+	lastStats  RenderStats
+)
+
+// State for the next draw from the current settings
+func currentState(format VertexFormat, primitive Primitive, texture uint32) batchState {
+	scissor, clipped := currentScissor()
+	return batchState{
+		format:    format,
+		texture:   texture,
+		primitive: primitive,
+		blend:     currentBlendMode,
+		transform: projectionMatrix(),
+		shader:    currentShaderID(),
+		scissor:   scissor,
+		clipped:   clipped,
+		stencil:   currentStencil,
+	}
+}
+
+// Add geometry to the batch, flushing first if the state changes
+// or the batch is full. Indices are relative to the given vertices.
+func batchAppend(state batchState, vertices []float32, indices []uint32) {
+	stride := state.format.Stride()
+	count := len(vertices) / stride
+	if len(batch.indices) > 0 {
+		switch {
+		case batch.state != state:
+			frameStats.StateFlushes++
+			flushBatch()
+		case len(batch.vertices)/stride+count > maxBatchVertices:
+			frameStats.FullFlushes++
+			flushBatch()
+		}
+	}
+	batch.state = state
+
+	base := uint32(len(batch.vertices) / stride)
+	batch.vertices = append(batch.vertices, vertices...)
+	for _, index := range indices {
+		batch.indices = append(batch.indices, base+index)
+	}
+	frameStats.Vertices += count
+}
+
+// Send the batched geometry to the backend and empty the batch
+func flushBatch() {
+	if len(batch.indices) == 0 {
+		return
+	}
+	backend.Draw(DrawCommand{
+		Format:    batch.state.format,
+		Primitive: batch.state.primitive,
+		Texture:   batch.state.texture,
+		Blend:     batch.state.blend,
+		Transform: batch.state.transform,
+		Shader:    batch.state.shader,
+		Scissor:   batch.state.scissor,
+		Clipped:   batch.state.clipped,
+		Stencil:   batch.state.stencil,
+		Vertices:  batch.vertices,
+		Indices:   batch.indices,
+	})
+
+	frameStats.DrawCalls++
+	batch.vertices = batch.vertices[:0]
+	batch.indices = batch.indices[:0]
+}
+
+// Finish the frame: flush what is left and roll over the stats
+func endFrameBatch() {
+	flushBatch()
+	lastStats = frameStats
+	frameStats = RenderStats{}
+}
+
+// GetRenderStats returns the counters of the last presented frame.
+func GetRenderStats() RenderStats {
+	return lastStats
+}
@@ -0,0 +1,99 @@
+package graphics
+
+import "fmt"
+
+// BlendMode decides how drawn pixels combine with what is already there.
+type BlendMode int
+
+const (
+	BlendAlpha         BlendMode = iota // normal transparency (default)
+	BlendAdditive                       // adds light, for glows and particles
+	BlendMultiply                       // darkens, for shadows and tinting
+	BlendScreen                         // lightens without blowing out
+	BlendPremultiplied                  // alpha blending for premultiplied colors
+	BlendReplace                        // overwrites, no blending
+)
+
+// blendFactor is a source or destination factor of the blend equation
+// result = src * srcFactor + dst * dstFactor
+type blendFactor int
+
+const (
+	factorZero blendFactor = iota
+	factorOne
+	factorSrcAlpha
+	factorOneMinusSrcAlpha
+	factorSrcColor
+	factorOneMinusSrcColor
+	factorDstColor
+	factorOneMinusDstColor
+)
+
+// blendFunc holds the factors of a mode, colors and alpha separately
+type blendFunc struct {
+	srcRGB, dstRGB     blendFactor
+	srcAlpha, dstAlpha blendFactor
+}
+
+// Factors for each mode, shared by the backends so they blend alike
+var blendFuncs = map[BlendMode]blendFunc{
+	BlendAlpha:         {factorSrcAlpha, factorOneMinusSrcAlpha, factorOne, factorOneMinusSrcAlpha},
+	BlendAdditive:      {factorSrcAlpha, factorOne, factorOne, factorOne},
+	BlendMultiply:      {factorDstColor, factorOneMinusSrcAlpha, factorOne, factorOneMinusSrcAlpha},
+	BlendScreen:        {factorOneMinusDstColor, factorOne, factorOne, factorOneMinusSrcAlpha},
+	BlendPremultiplied: {factorOne, factorOneMinusSrcAlpha, factorOne, factorOneMinusSrcAlpha},
+	BlendReplace:       {factorOne, factorZero, factorOne, factorZero},
+}
+
+// Mode used by the Draw* functions
+var currentBlendMode = BlendAlpha
+
+// SetBlendMode changes how everything drawn afterwards is blended
+func SetBlendMode(mode BlendMode) {
+	if _, ok := blendFuncs[mode]; !ok {
+		mode = BlendAlpha
+	}
+	currentBlendMode = mode
+}
+
+// GetBlendMode returns the current blend mode
+func GetBlendMode() BlendMode {
+	return currentBlendMode
+}
+
+// Names of the modes in text and JSON
+var blendNames = map[BlendMode]string{
+	BlendAlpha:         "alpha",
+	BlendAdditive:      "additive",
+	BlendMultiply:      "multiply",
+	BlendScreen:        "screen",
+	BlendPremultiplied: "premultiplied",
+	BlendReplace:       "replace",
+}
+
+// String returns the mode's name, like "additive"
+func (m BlendMode) String() string {
+	if name, ok := blendNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("BlendMode(%d)", int(m))
+}
+
+// MarshalText writes the mode by name
+func (m BlendMode) MarshalText() ([]byte, error) {
+	if _, ok := blendNames[m]; !ok {
+		return nil, fmt.Errorf("unknown blend mode %d", int(m))
+	}
+	return []byte(blendNames[m]), nil
+}
+
+// UnmarshalText reads a mode by name
+func (m *BlendMode) UnmarshalText(text []byte) error {
+	for mode, name := range blendNames {
+		if name == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown blend mode %q", text)
+}
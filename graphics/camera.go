@@ -0,0 +1,47 @@
+package graphics
+
+// Camera2D describes a view into the world.
+// The world point at Target is drawn at screen point Offset,
+// rotated around it by Rotation degrees and scaled by Zoom.
+type Camera2D struct {
+	OffsetX, OffsetY float32 // Screen position of the target, usually the window center
+	TargetX, TargetY float32 // World position the camera looks at
+	Rotation         float32 // Rotation in degrees
+	Zoom             float32 // Scale, 1 draws world units as pixels (0 is treated as 1)
+}
+
+// Transform applied to everything drawn, set by BeginMode2D
+var viewMatrix = MatrixIdentity()
+
+// Matrix returns the world to screen transform of the camera
+func (c Camera2D) Matrix() Matrix {
+	zoom := c.Zoom
+	if zoom == 0 {
+		zoom = 1
+	}
+	return MatrixTranslate(c.OffsetX, c.OffsetY).
+		Mul(MatrixScale(zoom, zoom)).
+		Mul(MatrixRotate(c.Rotation)).
+		Mul(MatrixTranslate(-c.TargetX, -c.TargetY))
+}
+
+// WorldToScreen converts a world position to window coordinates
+func (c Camera2D) WorldToScreen(x, y float32) (float32, float32) {
+	return c.Matrix().Apply(x, y)
+}
+
+// ScreenToWorld converts window coordinates to a world position
+// It accepts GetMousePosition directly: camera.ScreenToWorld(GetMousePosition())
+func (c Camera2D) ScreenToWorld(x, y float32) (float32, float32) {
+	return c.Matrix().Invert().Apply(x, y)
+}
+
+// BeginMode2D draws everything after it through the camera
+func BeginMode2D(camera Camera2D) {
+	viewMatrix = camera.Matrix()
+}
+
+// EndMode2D goes back to drawing in window coordinates
+func EndMode2D() {
+	viewMatrix = MatrixIdentity()
+}
@@ -0,0 +1,83 @@
+package graphics
+
+import (
+	"image"
+)
+
+// StencilMode is how a DrawCommand uses the mask.
+type StencilMode int
+
+const (
+	StencilOff     StencilMode = iota // mask ignored
+	StencilWrite                      // draw into the mask only, nothing visible
+	StencilInside                     // draw only where the mask is set
+	StencilOutside                    // draw only where the mask is not set
+)
+
+// Scissor rectangles started with BeginScissor, innermost last
+var scissorStack []image.Rectangle
+
+// Mask mode used by the Draw* functions
+var currentStencil = StencilOff
+
+// BeginScissor restricts drawing to a rectangle until EndScissor
+// The rectangle goes through the camera and current transform, the
+// area clipped is its bounding box on screen. Nested scissors only
+// draw where they overlap their parents.
+func BeginScissor(x, y, width, height float32) {
+	m := viewMatrix.Mul(modelMatrix)
+	x0, y0 := m.Apply(x, y)
+	x1, y1 := m.Apply(x+width, y)
+	x2, y2 := m.Apply(x+width, y+height)
+	x3, y3 := m.Apply(x, y+height)
+
+	rect := image.Rect(
+		int(floor32(min(x0, x1, x2, x3))),
+		int(floor32(min(y0, y1, y2, y3))),
+		int(ceil32(max(x0, x1, x2, x3))),
+		int(ceil32(max(y0, y1, y2, y3))),
+	)
+	if len(scissorStack) > 0 {
+		rect = rect.Intersect(scissorStack[len(scissorStack)-1])
+	}
+	scissorStack = append(scissorStack, rect)
+}
+
+// EndScissor removes the last scissor rectangle
+func EndScissor() {
+	if len(scissorStack) > 0 {
+		scissorStack = scissorStack[:len(scissorStack)-1]
+	}
+}
+
+// BeginMask clears the mask, then everything drawn until EndMask
+// is written into the mask instead of the screen. Transparent
+// pixels of images are left out, so an image can shape the mask.
+func BeginMask() {
+	flushBatch()
+	backend.ClearMask()
+	currentStencil = StencilWrite
+}
+
+// EndMask stops writing the mask, draws after it only show inside the mask
+func EndMask() {
+	currentStencil = StencilInside
+}
+
+// EndMaskInverted stops writing the mask, draws after it only show outside the mask
+func EndMaskInverted() {
+	currentStencil = StencilOutside
+}
+
+// ClearMask stops masking, draws show everywhere again
+func ClearMask() {
+	currentStencil = StencilOff
+}
+
+// Scissor part of the batch state
+func currentScissor() (image.Rectangle, bool) {
+	if len(scissorStack) == 0 {
+		return image.Rectangle{}, false
+	}
+	return scissorStack[len(scissorStack)-1], true
+}
@@ -0,0 +1,278 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Starting size of a font's glyph atlas, it doubles when full
+const fontAtlasSize = 256
+
+// Empty pixels around each glyph so neighbours don't bleed in
+const glyphPadding = 1
+
+// Font is a TrueType or OpenType font rasterized at one pixel size
+// Glyphs are rendered the first time they are drawn, into an atlas
+// texture that grows as needed. Metrics are in pixels at Size.
+type Font struct {
+	Size       float32
+	Ascent     float32 // above the baseline
+	Descent    float32 // below the baseline
+	LineHeight float32
+
+	face   font.Face
+	glyphs map[rune]fontGlyph
+
+	// Atlas pixels top-down, white with the glyph coverage in alpha
+	pixels   *image.NRGBA
+	image    *Image
+	dirty    image.Rectangle // pixels changed since the last upload
+	penX     int             // where the next glyph goes on the current shelf
+	shelfY   int
+	shelfEnd int // bottom of the tallest glyph on the shelf
+
+	// Reused every draw
+	vertices []float32
+	indices  []uint32
+}
+
+type fontGlyph struct {
+	src     Rectangle // atlas pixels, empty for blank glyphs like space
+	offset  Vector2   // from the pen on the baseline to the glyph's top-left
+	advance float32
+}
+
+// LoadFont loads a .ttf or .otf file at a size in pixels
+func LoadFont(path string, size float32) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFont(data, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// NewFont creates a font from TrueType or OpenType data at a size in pixels
+func NewFont(data []byte, size float32) (*Font, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("font size must be positive, got %v", size)
+	}
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	// At 72 DPI a point is a pixel
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    float64(size),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := face.Metrics()
+	return &Font{
+		Size:       size,
+		Ascent:     fixedToFloat(metrics.Ascent),
+		Descent:    fixedToFloat(metrics.Descent),
+		LineHeight: fixedToFloat(metrics.Height),
+		face:       face,
+		glyphs:     make(map[rune]fontGlyph),
+		pixels:     image.NewNRGBA(image.Rect(0, 0, fontAtlasSize, fontAtlasSize)),
+	}, nil
+}
+
+// Delete frees the glyph atlas texture
+func (f *Font) Delete() {
+	if f.image != nil {
+		f.image.Delete()
+		f.image = nil
+	}
+	f.face.Close()
+}
+
+// Glyph for a rune, rasterized into the atlas the first time
+// Runes the font lacks use its replacement character.
+func (f *Font) glyph(r rune) fontGlyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	dr, mask, maskp, advance, ok := f.face.Glyph(fixed.Point26_6{}, r)
+	if !ok && r != unicode.ReplacementChar {
+		g := f.glyph(unicode.ReplacementChar)
+		f.glyphs[r] = g
+		return g
+	}
+
+	g := fontGlyph{
+		offset:  Vec2(float32(dr.Min.X), float32(dr.Min.Y)),
+		advance: fixedToFloat(advance),
+	}
+	if ok && !dr.Empty() {
+		x, y := f.place(dr.Dx(), dr.Dy())
+		for py := 0; py < dr.Dy(); py++ {
+			for px := 0; px < dr.Dx(); px++ {
+				a := color.AlphaModel.Convert(mask.At(maskp.X+px, maskp.Y+py)).(color.Alpha).A
+				f.pixels.SetNRGBA(x+px, y+py, color.NRGBA{255, 255, 255, a})
+			}
+		}
+		g.src = Rect(float32(x), float32(y), float32(dr.Dx()), float32(dr.Dy()))
+		f.dirty = f.dirty.Union(image.Rect(x, y, x+dr.Dx(), y+dr.Dy()))
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+// Find room for a w x h glyph on the shelves, growing the atlas if it is full
+// Glyphs already placed keep their positions.
+func (f *Font) place(w, h int) (int, int) {
+	for {
+		size := f.pixels.Bounds().Size()
+		if f.penX+w > size.X {
+			// Start a new shelf below the current one
+			f.penX, f.shelfY = 0, f.shelfEnd
+		}
+		if f.penX+w <= size.X && f.shelfY+h <= size.Y {
+			x, y := f.penX, f.shelfY
+			f.penX += w + glyphPadding
+			f.shelfEnd = max(f.shelfEnd, y+h+glyphPadding)
+			return x, y
+		}
+
+		// Grow the shorter side
+		grown := size
+		if size.X <= size.Y {
+			grown.X *= 2
+		} else {
+			grown.Y *= 2
+		}
+		pixels := image.NewNRGBA(image.Rectangle{Max: grown})
+		for y := 0; y < size.Y; y++ {
+			copy(pixels.Pix[y*pixels.Stride:], f.pixels.Pix[y*f.pixels.Stride:y*f.pixels.Stride+size.X*4])
+		}
+		f.pixels = pixels
+	}
+}
+
+// Upload the glyphs added since the last draw
+// Only the changed area is sent, the texture is recreated when the atlas grows.
+func (f *Font) upload() {
+	size := f.pixels.Bounds().Size()
+	if f.image != nil && (int(f.image.Width) != size.X || int(f.image.Height) != size.Y) {
+		f.image.Delete()
+		f.image = nil
+	}
+	if f.image == nil {
+		f.image = &Image{
+			TextureID: backend.NewTexture(size.X, size.Y, f.rowsBottomUp(f.pixels.Bounds())),
+			Width:     int32(size.X),
+			Height:    int32(size.Y),
+		}
+	} else if !f.dirty.Empty() {
+		r := f.dirty
+		backend.UpdateTexture(f.image.TextureID, r.Min.X, size.Y-r.Max.Y, r.Dx(), r.Dy(), f.rowsBottomUp(r))
+	}
+	f.dirty = image.Rectangle{}
+}
+
+// Copy an area of the atlas with its rows bottom-up, the way the backend wants them
+func (f *Font) rowsBottomUp(r image.Rectangle) []byte {
+	row := r.Dx() * 4
+	pixels := make([]byte, row*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		start := f.pixels.PixOffset(r.Min.X, y)
+		copy(pixels[(r.Max.Y-1-y)*row:], f.pixels.Pix[start:start+row])
+	}
+	return pixels
+}
+
+// DrawTextEx draws text with a loaded font, (x, y) is the top-left of the first line
+// size is the line's pixel size, spacing adds pixels between letters.
+// Letters are kerned and lines break at '\n'.
+func DrawTextEx(f *Font, text string, x, y, size, spacing float32, tint Color) {
+	if f == nil || text == "" {
+		return
+	}
+	scale := size / f.Size
+
+	f.vertices, f.indices = f.vertices[:0], f.indices[:0]
+	var quads []fontGlyph
+	var positions []Vector2
+	f.layout(text, scale, spacing, func(g fontGlyph, penX, penY float32) {
+		if g.src.Width > 0 {
+			quads = append(quads, g)
+			positions = append(positions, Vec2(x+penX, y+penY))
+		}
+	})
+	if len(quads) == 0 {
+		return
+	}
+
+	// Every glyph is in the atlas now, upload it once before building quads
+	f.upload()
+	iw, ih := float32(f.image.Width), float32(f.image.Height)
+	for i, g := range quads {
+		dst := Rect(positions[i].X+g.offset.X*scale, positions[i].Y+g.offset.Y*scale, g.src.Width*scale, g.src.Height*scale)
+		u := [2]float32{g.src.X / iw, (g.src.X + g.src.Width) / iw}
+		v := [2]float32{1 - g.src.Y/ih, 1 - (g.src.Y+g.src.Height)/ih} // rows are stored bottom-up
+
+		base := uint32(len(f.vertices) / 8)
+		for _, c := range [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+			px, py := transformPoint(dst.X+float32(c[0])*dst.Width, dst.Y+float32(c[1])*dst.Height)
+			f.vertices = append(f.vertices, px, py, u[c[0]], v[c[1]], tint.R, tint.G, tint.B, tint.A)
+		}
+		f.indices = append(f.indices, base, base+1, base+2, base+2, base+3, base)
+	}
+	drawTexturedQuad(f.image, f.vertices, f.indices)
+}
+
+// MeasureTextEx returns the width and height of text drawn with DrawTextEx
+func MeasureTextEx(f *Font, text string, size, spacing float32) Vector2 {
+	if f == nil || text == "" {
+		return Vector2{}
+	}
+	scale := size / f.Size
+	var width float32
+	f.layout(text, scale, spacing, func(g fontGlyph, penX, penY float32) {
+		width = max(width, penX+g.advance*scale)
+	})
+	lines := strings.Count(text, "\n") + 1
+	return Vec2(width, float32(lines)*f.LineHeight*scale)
+}
+
+// Walk the glyphs of text, giving each one's pen position from the top-left
+// The pen is on the baseline, Ascent below the top of its line.
+func (f *Font) layout(text string, scale, spacing float32, visit func(g fontGlyph, penX, penY float32)) {
+	var penX, line float32
+	prev := rune(-1)
+	for _, r := range text {
+		if r == '\n' {
+			penX, prev = 0, -1
+			line += f.LineHeight * scale
+			continue
+		}
+		if prev >= 0 {
+			penX += fixedToFloat(f.face.Kern(prev, r))*scale + spacing
+		}
+		g := f.glyph(r)
+		visit(g, penX, line+f.Ascent*scale)
+		penX += g.advance * scale
+		prev = r
+	}
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
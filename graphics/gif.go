@@ -0,0 +1,234 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+	"sort"
+)
+
+// AnimatedGIF holds every frame of a GIF, composited to the full
+// canvas and packed into an atlas
+type AnimatedGIF struct {
+	Atlas         *Atlas
+	Frames        []AtlasRegion
+	Delays        []float64 // seconds per frame
+	Width, Height int
+	LoopCount     int // 0 loops forever, -1 plays once, n plays n+1 times
+
+	starts   []float64 // time each frame starts
+	duration float64
+}
+
+// LoadAnimatedGIF loads all frames of a GIF file
+// LoadImage only keeps the first one.
+func LoadAnimatedGIF(filePath string) (*AnimatedGIF, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoded, err := gif.DecodeAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return NewAnimatedGIF(decoded)
+}
+
+// NewAnimatedGIF composites and uploads decoded GIF frames
+func NewAnimatedGIF(g *gif.GIF) (*AnimatedGIF, error) {
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("gif: no frames")
+	}
+	frames := compositeGIF(g)
+	images := make(map[string]image.Image, len(frames))
+	for i, frame := range frames {
+		images[gifFrameName(i)] = frame
+	}
+	// Pages are at least one frame big, so large GIFs get a page per frame
+	// instead of failing to fit. The GPU may still refuse huge textures.
+	const extrude = 1
+	size := frames[0].Bounds().Size()
+	packed, err := PackAtlas(images, AtlasOptions{
+		MaxWidth:  max(2048, size.X+2*extrude),
+		MaxHeight: max(2048, size.Y+2*extrude),
+		Extrude:   extrude,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := frames[0].Bounds()
+	a := &AnimatedGIF{
+		Atlas:     packed,
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+		LoopCount: g.LoopCount,
+	}
+	for i := range frames {
+		region, _ := packed.Region(gifFrameName(i))
+		a.Frames = append(a.Frames, region)
+
+		// Browsers show 0 and 10ms delays at 100ms, so do the same
+		delay := 10
+		if i < len(g.Delay) && g.Delay[i] > 1 {
+			delay = g.Delay[i]
+		}
+		a.starts = append(a.starts, a.duration)
+		a.Delays = append(a.Delays, float64(delay)/100)
+		a.duration += a.Delays[i]
+	}
+	return a, nil
+}
+
+func gifFrameName(i int) string {
+	return fmt.Sprintf("%05d", i)
+}
+
+// Draw each frame over what the previous ones left, following their disposal
+func compositeGIF(g *gif.GIF) []*image.RGBA {
+	width, height := g.Config.Width, g.Config.Height
+	if width == 0 || height == 0 {
+		var bounds image.Rectangle
+		for _, frame := range g.Image {
+			bounds = bounds.Union(frame.Bounds())
+		}
+		width, height = bounds.Max.X, bounds.Max.Y
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	frames := make([]*image.RGBA, 0, len(g.Image))
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		frames = append(frames, cloneRGBA(canvas))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			// Browsers clear to transparent rather than the background color
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return frames
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	clone := image.NewRGBA(img.Rect)
+	copy(clone.Pix, img.Pix)
+	return clone
+}
+
+// Duration returns the length of one play in seconds
+func (a *AnimatedGIF) Duration() float64 {
+	return a.duration
+}
+
+// FrameAt returns the frame shown after elapsed seconds
+// It stays on the last frame once the GIF's loops are done.
+func (a *AnimatedGIF) FrameAt(elapsed float64) int {
+	if a.finishedAt(elapsed) {
+		return len(a.Frames) - 1
+	}
+	t := elapsed
+	if a.duration > 0 {
+		t -= float64(int(elapsed/a.duration)) * a.duration
+	}
+	return max(sort.SearchFloat64s(a.starts, t+1e-9)-1, 0)
+}
+
+// Whether the GIF has played all its loops after elapsed seconds
+func (a *AnimatedGIF) finishedAt(elapsed float64) bool {
+	plays := a.LoopCount + 1
+	if a.LoopCount < 0 {
+		plays = 1
+	}
+	return a.LoopCount != 0 && elapsed >= a.duration*float64(plays)
+}
+
+// DrawFrame draws a frame with options, Width and Height default to the GIF size
+func (a *AnimatedGIF) DrawFrame(frame int, opts DrawOptions) {
+	if frame < 0 || frame >= len(a.Frames) {
+		return
+	}
+	r := a.Frames[frame]
+	drawRegion(r.Image, r.Src, opts)
+}
+
+// Delete frees the frame textures
+func (a *AnimatedGIF) Delete() {
+	a.Atlas.Delete()
+	a.Frames = nil
+}
+
+// GIFPlayer plays an AnimatedGIF by elapsed time
+// Several players can share one GIF.
+type GIFPlayer struct {
+	GIF     *AnimatedGIF
+	Speed   float64 // playback rate, 1 is normal speed
+	Elapsed float64 // seconds played so far
+	paused  bool
+}
+
+// NewGIFPlayer creates a player at the first frame
+func NewGIFPlayer(g *AnimatedGIF) *GIFPlayer {
+	return &GIFPlayer{GIF: g, Speed: 1}
+}
+
+// Update advances the player by the last frame's delta time
+func (p *GIFPlayer) Update() {
+	p.UpdateBy(GetDeltaTime())
+}
+
+// UpdateBy advances the player by dt seconds
+func (p *GIFPlayer) UpdateBy(dt float64) {
+	if !p.paused && !p.IsFinished() {
+		p.Elapsed += dt * p.Speed
+	}
+}
+
+// Reset goes back to the first frame
+func (p *GIFPlayer) Reset() {
+	p.Elapsed = 0
+}
+
+// Pause stops the player on its current frame
+func (p *GIFPlayer) Pause() {
+	p.paused = true
+}
+
+// Resume continues a paused player
+func (p *GIFPlayer) Resume() {
+	p.paused = false
+}
+
+// IsFinished reports whether a GIF that doesn't loop forever is done
+func (p *GIFPlayer) IsFinished() bool {
+	return p.GIF.finishedAt(p.Elapsed)
+}
+
+// Frame returns the frame to show now
+func (p *GIFPlayer) Frame() int {
+	return p.GIF.FrameAt(p.Elapsed)
+}
+
+// Draw draws the current frame at its own size
+func (p *GIFPlayer) Draw(x, y float32) {
+	p.DrawEx(DrawOptions{X: x, Y: y})
+}
+
+// DrawEx draws the current frame with options
+func (p *GIFPlayer) DrawEx(opts DrawOptions) {
+	p.GIF.DrawFrame(p.Frame(), opts)
+}
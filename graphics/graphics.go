@@ -1,79 +1,93 @@
 package graphics
 
 import (
+	"fmt"
 	"runtime"
-	"github.com/go-gl/gl/v3.3-core/gl"
-	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 // Global states for the graphics system
 var (
-	window *glfw.Window
-	shaderProgram uint32
-	currentVAO uint32
-	currentVBO uint32
 	windowWidth, windowHeight int
+	surfaceWidth, surfaceHeight int // window or current render target
 )
 
 func init() {
 	runtime.LockOSThread()
 }
 
-// Initialize for graphics system
-func Init(width, height int, title string) error {
+// Initialize without a window, drawing into memory with the software backend
+// Use Screenshot to get the rendered frame. Calling it again keeps the
+// loaded images and starts over with a blank surface of the new size.
+// Build with -tags pixu_headless to leave out glfw and OpenGL, then it
+// needs no cgo and no X11 or GL headers.
+func InitHeadless(width, height int) error {
 	windowWidth, windowHeight = width, height
-	
-	if err := glfw.Init(); err != nil {
-		return err
+	surfaceWidth, surfaceHeight = width, height
+	targetStack = nil
+	if soft, ok := backend.(*SoftwareBackend); ok {
+		soft.Resize(width, height)
+		return nil
 	}
+	backend = NewSoftwareBackend(width, height)
+	return initResources()
+}
 
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-
-	var err error
-	window, err = glfw.CreateWindow(width, height, title, nil, nil)
-	if err != nil {
-		return err
-	}
-	window.MakeContextCurrent()
-	setupInput(window)
-	if err := gl.Init(); err != nil {
-		return err
+// Create what the Draw* functions need once a backend is ready
+func initResources() error {
+	whiteTexture = backend.NewTexture(1, 1, []byte{255, 255, 255, 255})
+	if err := loadFontAtlas(); err != nil {
+		return fmt.Errorf("font atlas: %w", err)
 	}
-
-	// Setup viewport and OpenGL settings
-	gl.Viewport(0, 0, int32(width), int32(height))
-	setupShaders()
-	setupBuffers()
-	
-	initTextureSystem()
-	loadFontAtlas()
 	InitFps(60)
 	return nil
 }
 
+// ResetState puts every drawing setting back to its default: camera,
+// transforms, blend mode, scissors, mask, shader and anti-aliasing.
+// Open render targets are ended and post processing is turned off.
+// Loaded images, fonts and shaders stay valid.
+func ResetState() {
+	flushBatch()
+	if activePost != nil {
+		SetPostProcess(nil)
+	}
+	for len(targetStack) > 0 {
+		EndRenderTarget()
+	}
+	viewMatrix, modelMatrix, modelStack = MatrixIdentity(), MatrixIdentity(), nil
+	currentBlendMode = BlendAlpha
+	scissorStack, currentStencil = nil, StencilOff
+	currentShader = nil
+	antiAlias = false
+}
+
 // Check if window should continue running
 func ShouldContinue() bool {
-	return !window.ShouldClose()
+	return !windowShouldClose()
 }
 
 // Clear the background with a color
 func ClearBackground(color Color) {
-	gl.ClearColor(color.R, color.G, color.B, color.A)
-	gl.Clear(gl.COLOR_BUFFER_BIT)
+	flushBatch()
+	backend.Clear(color)
 }
 
 // Present the current frame
 // This swaps the buffers and polls events
 // It should be called after all drawing operations are done.
 func Present() {
-	window.SwapBuffers()
-	glfw.PollEvents()
+	if activePost != nil {
+		activePost.finish()
+	}
+	endFrameBatch()
+	swapWindow()
+	if activePost != nil {
+		activePost.begin()
+	}
 }
 
 // Close the graphics system
 // This should be called when the application is done with graphics
 func Close() {
-	glfw.Terminate()
+	closeWindow()
 }
@@ -1,15 +1,12 @@
 package graphics
 
 import (
+	"fmt"
 	"image"
 	_ "image/gif" // Support GIF
 	_ "image/jpeg"
 	_ "image/png"
-	"math"
 	"os"
-	"unsafe"
-
-	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 // Image struct represents a loaded image texture
@@ -30,68 +27,40 @@ type DrawOptions struct {
 	SrcW, SrcH    float32 // Source rect Width, Height
 }
 
-// Global texture shader program
-var textureShaderProgram uint32
-var textureVAO, textureVBO uint32
-
-// Initialize texture system
-func initTextureSystem() {
-	setupTextureShaders()
-	setupTextureBuffers()
-}
-
 // Load image mn file - supports PNG, JPEG, GIF
 func LoadImage(filePath string) (*Image, error) {
-	// Open l file
-	file, err := os.Open(filePath)
+	img, err := decodeImageFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	// Decode l image
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return nil, err
-	}
+	loaded := NewImageFromImage(img)
+	loaded.filePath = filePath
+	return loaded, nil
+}
 
-	// Convert l RGBA
+// Create an image from pixels already in memory
+func NewImageFromImage(img image.Image) *Image {
+	// Convert l RGBA, rows bottom-up like the backend expects
 	bounds := img.Bounds()
-	width := bounds.Max.X - bounds.Min.X
-	height := bounds.Max.Y - bounds.Min.Y
+	width := bounds.Dx()
+	height := bounds.Dy()
 
-	rgba := image.NewRGBA(bounds)
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
 	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			rgba.Set(x, bounds.Max.Y-y-1, img.At(x, y))
+			rgba.Set(x-bounds.Min.X, bounds.Max.Y-y-1, img.At(x, y))
 		}
 	}
 
-	// Create OpenGL texture
-	var textureID uint32
-	gl.GenTextures(1, &textureID)
-	gl.BindTexture(gl.TEXTURE_2D, textureID)
-
-	// Texture parameters 
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-
-	// Upload texture data
-	gl.TexImage2D(
-		gl.TEXTURE_2D, 0, gl.RGBA,
-		int32(width), int32(height), 0,
-		gl.RGBA, gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix),
-	)
+	// Upload to the backend
+	textureID := backend.NewTexture(width, height, rgba.Pix)
 
 	return &Image{
 		TextureID: textureID,
 		Width:     int32(width),
 		Height:    int32(height),
-		filePath:  filePath,
-	}, nil
+	}
 }
 
 // Draw image at specific position
@@ -180,40 +149,22 @@ func DrawImageEx(img *Image, opts DrawOptions) {
 		opts.Tint = WHITE // Default tint color
 	}
 	
-	glX, glY := screenToGL(opts.X, opts.Y)
-	glW := opts.Width / float32(windowWidth) * 2.0
-	glH := opts.Height / float32(windowHeight) * 2.0
-
 	// Texture coordinates
 	texX := opts.SrcX / float32(img.Width)
 	texY := opts.SrcY / float32(img.Height)
 	texW := opts.SrcW / float32(img.Width)
 	texH := opts.SrcH / float32(img.Height)
 
-	// Calculate rotation angle (in degrees )
-	rotation := opts.Rotation * math.Pi / 180.0
+	// Rotate the corners around the image center,
+	// then apply the current transform
+	rotation := MatrixTranslate(opts.X+opts.Width/2, opts.Y+opts.Height/2).
+		Mul(MatrixRotate(opts.Rotation)).
+		Mul(MatrixTranslate(-opts.X-opts.Width/2, -opts.Y-opts.Height/2))
 
-	// Image center in normalized coordinates (relative to the window size)
-	centerX := glX + glW/2
-	centerY := glY - glH/2
-
-	rotatePoint := func(x, y, cx, cy float32, angle float32) (float32, float32) {
-		// Translate point to origin (by subtracting the center)
-		translatedX := x - cx
-		translatedY := y - cy
-
-		// Apply rotation matrix
-		rotatedX := translatedX*float32(math.Cos(float64(angle))) - translatedY*float32(math.Sin(float64(angle)))
-		rotatedY := translatedX*float32(math.Sin(float64(angle))) + translatedY*float32(math.Cos(float64(angle)))
-
-		// Translate back to original position
-		return rotatedX + cx, rotatedY + cy
-	}
-
-	tlx, tly := rotatePoint(glX, glY, centerX, centerY, rotation) // top-left
-	trx, try := rotatePoint(glX + glW, glY, centerX, centerY, rotation) // top-right
-	brx, bry := rotatePoint(glX + glW, glY - glH, centerX, centerY, rotation) // bottom-right
-	blx, bly := rotatePoint(glX, glY - glH, centerX, centerY, rotation)
+	tlx, tly := transformPoint(rotation.Apply(opts.X, opts.Y)) // top-left
+	trx, try := transformPoint(rotation.Apply(opts.X+opts.Width, opts.Y)) // top-right
+	brx, bry := transformPoint(rotation.Apply(opts.X+opts.Width, opts.Y+opts.Height)) // bottom-right
+	blx, bly := transformPoint(rotation.Apply(opts.X, opts.Y+opts.Height)) // bottom-left
 
 	// Vertex data
 	vertices := []float32{
@@ -228,39 +179,71 @@ func DrawImageEx(img *Image, opts DrawOptions) {
 	drawTexturedQuad(img, vertices, indices)
 }
 
-// Helper function to draw a textured quad
-// This function sets up the vertex array object (VAO), vertex buffer object (VBO),
-// and element buffer object (EBO) for rendering the textured quad.
+// Helper function to queue a textured quad
+// Quads sharing the same texture end up in one draw call.
 func drawTexturedQuad(img *Image, vertices []float32, indices []uint32) {
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-
-	// Bind texture
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, img.TextureID)
-
-	// Setup buffers
-	gl.BindVertexArray(textureVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, textureVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(unsafe.Sizeof(vertices[0]))*len(vertices), gl.Ptr(vertices), gl.DYNAMIC_DRAW)
+	batchAppend(currentState(TextureVertex, Triangles, img.TextureID), vertices, indices)
+}
 
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(unsafe.Sizeof(indices[0]))*len(indices), gl.Ptr(indices), gl.DYNAMIC_DRAW)
+// Delete image texture
+// This function deletes the backend texture associated with the image.
+func (img *Image) Delete() {
+	if batch.state.texture == img.TextureID {
+		flushBatch()
+	}
+	backend.DeleteTexture(img.TextureID)
+	img.TextureID = 0
+}
 
-	// Enable blending for transparency
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+// Draw part of an image stretched over a rectangle
+// src is in image pixels from the top-left corner, the way image
+// editors and sprite tools count (DrawOptions.SrcY counts from the bottom).
+func DrawImageRegion(img *Image, src, dst Rectangle, tint Color) {
+	if img == nil {
+		return
+	}
+	DrawImageEx(img, regionOptions(img, src, dst, tint))
+}
 
-	// Draw
-	gl.UseProgram(textureShaderProgram)
-	gl.DrawElements(gl.TRIANGLES, int32(len(indices)), gl.UNSIGNED_INT, nil)
+// Draw a top-left based source rectangle with the rest of opts
+// Width and Height default to the source size.
+func drawRegion(img *Image, src Rectangle, opts DrawOptions) {
+	if opts.Width == 0 {
+		opts.Width = src.Width
+	}
+	if opts.Height == 0 {
+		opts.Height = src.Height
+	}
+	region := regionOptions(img, src, Rect(opts.X, opts.Y, opts.Width, opts.Height), opts.Tint)
+	region.Rotation = opts.Rotation
+	DrawImageEx(img, region)
+}
 
-	gl.Disable(gl.BLEND)
-	gl.DeleteBuffers(1, &EBO)
+// DrawOptions for a top-left based source rectangle
+func regionOptions(img *Image, src, dst Rectangle, tint Color) DrawOptions {
+	return DrawOptions{
+		X:      dst.X,
+		Y:      dst.Y,
+		Width:  dst.Width,
+		Height: dst.Height,
+		Tint:   tint,
+		SrcX:   src.X,
+		SrcY:   float32(img.Height) - src.Y - src.Height,
+		SrcW:   src.Width,
+		SrcH:   src.Height,
+	}
 }
 
-// Delete image texture
-// This function deletes the OpenGL texture associated with the image.
-func (img *Image) Delete() {
-	gl.DeleteTextures(1, &img.TextureID)
+// Open and decode an image file without uploading it
+func decodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
 }
@@ -1,49 +1,5 @@
 package graphics
 
-import (
-	"github.com/go-gl/gl/v3.3-core/gl"
-	"github.com/go-gl/glfw/v3.3/glfw"
-)
-
-// Key constants - common keys
-const (
-	KeySpace     = glfw.KeySpace
-	KeyEscape    = glfw.KeyEscape
-	KeyEnter     = glfw.KeyEnter
-	KeyTab       = glfw.KeyTab
-	KeyBackspace = glfw.KeyBackspace
-	KeyDelete    = glfw.KeyDelete
-
-	// Arrow keys
-	KeyUp    = glfw.KeyUp
-	KeyDown  = glfw.KeyDown
-	KeyLeft  = glfw.KeyLeft
-	KeyRight = glfw.KeyRight
-
-	// WASD
-	KeyW = glfw.KeyW
-	KeyA = glfw.KeyA
-	KeyS = glfw.KeyS
-	KeyD = glfw.KeyD
-
-	// Numbers
-	Key0 = glfw.Key0
-	Key1 = glfw.Key1
-	Key2 = glfw.Key2
-	Key3 = glfw.Key3
-	Key4 = glfw.Key4
-	Key5 = glfw.Key5
-	Key6 = glfw.Key6
-	Key7 = glfw.Key7
-	Key8 = glfw.Key8
-	Key9 = glfw.Key9
-
-	// Mouse buttons
-	MouseLeft   = glfw.MouseButton1
-	MouseRight  = glfw.MouseButton2
-	MouseMiddle = glfw.MouseButton3
-)
-
 // Input state tracking
 var (
 	keysPressed      = make(map[int]bool)
@@ -64,20 +20,6 @@ var (
 	lastWindowWidth, lastWindowHeight int
 )
 
-// Setup input callbacks
-func setupInput(window *glfw.Window) {
-	// Keyboard callbacks
-	window.SetKeyCallback(keyCallback)
-
-	// Mouse callbacks
-	window.SetMouseButtonCallback(mouseCallback)
-	window.SetCursorPosCallback(mousePosCallback)
-	window.SetScrollCallback(scrollCallback)
-
-	// Window size callback
-	window.SetSizeCallback(windowSizeCallback)
-}
-
 // Update input state
 // Call this every frame to update input states
 func UpdateInput() {
@@ -109,19 +51,19 @@ func UpdateInput() {
 
 // Check if a key is pressed (continuously)
 // Returns true if the key is currently pressed down
-func IsKeyPressed(key glfw.Key) bool {
+func IsKeyPressed(key Key) bool {
 	return keysPressed[int(key)]
 }
 
 // Check if a key is just pressed (one frame only)
 // Returns true if the key was pressed this frame
-func IsKeyJustPressed(key glfw.Key) bool {
+func IsKeyJustPressed(key Key) bool {
 	return keysJustPressed[int(key)]
 }
 
 // Check if a key is just released (one frame only)
 // Returns true if the key was released this frame
-func IsKeyJustReleased(key glfw.Key) bool {
+func IsKeyJustReleased(key Key) bool {
 	return keysJustReleased[int(key)]
 }
 
@@ -129,19 +71,19 @@ func IsKeyJustReleased(key glfw.Key) bool {
 
 // Check if a mouse button is pressed (continuously)
 // Returns true if the mouse button is currently pressed down
-func IsMousePressed(button glfw.MouseButton) bool {
+func IsMousePressed(button MouseButton) bool {
 	return mousePressed[int(button)]
 }
 
 // Check if a mouse button is just pressed (one frame only)
 // Returns true if the mouse button was pressed this frame
-func IsMouseJustPressed(button glfw.MouseButton) bool {
+func IsMouseJustPressed(button MouseButton) bool {
 	return mouseJustPressed[int(button)]
 }
 
 // Check if a mouse button is just released (one frame only)
 // Returns true if the mouse button was released this frame
-func IsMouseJustReleased(button glfw.MouseButton) bool {
+func IsMouseJustReleased(button MouseButton) bool {
 	return mouseJustReleased[int(button)]
 }
 
@@ -189,43 +131,3 @@ func IsWindowResized() bool {
 func GetWindowSize() (int, int) {
 	return windowWidth, windowHeight
 }
-
-// ============= CALLBACKS =============
-
-func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	switch action {
-	case glfw.Press:
-		keysPressed[int(key)] = true
-		keysJustPressed[int(key)] = true
-	case glfw.Release:
-		keysPressed[int(key)] = false
-		keysJustReleased[int(key)] = true
-	}
-}
-
-func mouseCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
-	switch action {
-	case glfw.Press:
-		mousePressed[int(button)] = true
-		mouseJustPressed[int(button)] = true
-	case glfw.Release:
-		mousePressed[int(button)] = false
-		mouseJustReleased[int(button)] = true
-	}
-}
-
-func mousePosCallback(w *glfw.Window, xpos, ypos float64) {
-	mouseX = xpos
-	mouseY = ypos
-}
-
-func scrollCallback(w *glfw.Window, xoffset, yoffset float64) {
-	scrollDeltaX += xoffset
-	scrollDeltaY += yoffset
-}
-
-func windowSizeCallback(w *glfw.Window, width, height int) {
-	windowWidth = width
-	windowHeight = height
-	gl.Viewport(0, 0, int32(width), int32(height)) // Update viewport
-}
@@ -0,0 +1,110 @@
+//go:build !pixu_headless
+
+package graphics
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Key is a keyboard key, the same as glfw's
+type Key = glfw.Key
+
+// MouseButton is a mouse button, the same as glfw's
+type MouseButton = glfw.MouseButton
+
+// Key constants - common keys
+const (
+	KeySpace     = glfw.KeySpace
+	KeyEscape    = glfw.KeyEscape
+	KeyEnter     = glfw.KeyEnter
+	KeyTab       = glfw.KeyTab
+	KeyBackspace = glfw.KeyBackspace
+	KeyDelete    = glfw.KeyDelete
+
+	// Arrow keys
+	KeyUp    = glfw.KeyUp
+	KeyDown  = glfw.KeyDown
+	KeyLeft  = glfw.KeyLeft
+	KeyRight = glfw.KeyRight
+
+	// WASD
+	KeyW = glfw.KeyW
+	KeyA = glfw.KeyA
+	KeyS = glfw.KeyS
+	KeyD = glfw.KeyD
+
+	// Numbers
+	Key0 = glfw.Key0
+	Key1 = glfw.Key1
+	Key2 = glfw.Key2
+	Key3 = glfw.Key3
+	Key4 = glfw.Key4
+	Key5 = glfw.Key5
+	Key6 = glfw.Key6
+	Key7 = glfw.Key7
+	Key8 = glfw.Key8
+	Key9 = glfw.Key9
+
+	// Mouse buttons
+	MouseLeft   = glfw.MouseButton1
+	MouseRight  = glfw.MouseButton2
+	MouseMiddle = glfw.MouseButton3
+)
+
+// Setup input callbacks
+func setupInput(window *glfw.Window) {
+	// Keyboard callbacks
+	window.SetKeyCallback(keyCallback)
+
+	// Mouse callbacks
+	window.SetMouseButtonCallback(mouseCallback)
+	window.SetCursorPosCallback(mousePosCallback)
+	window.SetScrollCallback(scrollCallback)
+
+	// Window size callback
+	window.SetSizeCallback(windowSizeCallback)
+}
+
+// ============= CALLBACKS =============
+
+func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	switch action {
+	case glfw.Press:
+		keysPressed[int(key)] = true
+		keysJustPressed[int(key)] = true
+	case glfw.Release:
+		keysPressed[int(key)] = false
+		keysJustReleased[int(key)] = true
+	}
+}
+
+func mouseCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	switch action {
+	case glfw.Press:
+		mousePressed[int(button)] = true
+		mouseJustPressed[int(button)] = true
+	case glfw.Release:
+		mousePressed[int(button)] = false
+		mouseJustReleased[int(button)] = true
+	}
+}
+
+func mousePosCallback(w *glfw.Window, xpos, ypos float64) {
+	mouseX = xpos
+	mouseY = ypos
+}
+
+func scrollCallback(w *glfw.Window, xoffset, yoffset float64) {
+	scrollDeltaX += xoffset
+	scrollDeltaY += yoffset
+}
+
+func windowSizeCallback(w *glfw.Window, width, height int) {
+	windowWidth = width
+	windowHeight = height
+	flushBatch()
+	backend.Resize(width, height) // Update viewport
+	if len(targetStack) == 0 {
+		surfaceWidth, surfaceHeight = width, height
+	}
+}
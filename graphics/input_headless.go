@@ -0,0 +1,49 @@
+//go:build pixu_headless
+
+package graphics
+
+// Key is a keyboard key, numbered like glfw's
+type Key int
+
+// MouseButton is a mouse button, numbered like glfw's
+type MouseButton int
+
+// Key constants - common keys
+// There is no window to read them from, so they are never pressed.
+const (
+	KeySpace     Key = 32
+	KeyEscape    Key = 256
+	KeyEnter     Key = 257
+	KeyTab       Key = 258
+	KeyBackspace Key = 259
+	KeyDelete    Key = 261
+
+	// Arrow keys
+	KeyRight Key = 262
+	KeyLeft  Key = 263
+	KeyDown  Key = 264
+	KeyUp    Key = 265
+
+	// WASD
+	KeyW Key = 'W'
+	KeyA Key = 'A'
+	KeyS Key = 'S'
+	KeyD Key = 'D'
+
+	// Numbers
+	Key0 Key = '0'
+	Key1 Key = '1'
+	Key2 Key = '2'
+	Key3 Key = '3'
+	Key4 Key = '4'
+	Key5 Key = '5'
+	Key6 Key = '6'
+	Key7 Key = '7'
+	Key8 Key = '8'
+	Key9 Key = '9'
+
+	// Mouse buttons
+	MouseLeft   MouseButton = 0
+	MouseRight  MouseButton = 1
+	MouseMiddle MouseButton = 2
+)
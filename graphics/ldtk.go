@@ -0,0 +1,476 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// LDtkProject is a project made in LDtk
+// Levels sit at their world positions, see WorldLayout.
+type LDtkProject struct {
+	Levels          []*LDtkLevel
+	Tilesets        []*LDtkTileset
+	WorldLayout     string // Free, GridVania, LinearHorizontal or LinearVertical
+	WorldGridWidth  int
+	WorldGridHeight int
+	BackgroundColor Color
+
+	images []*Image
+}
+
+// LDtkTileset is a tileset image with its tile data
+type LDtkTileset struct {
+	UID        int
+	Identifier string
+	Image      *Image // nil for LDtk's built-in icons
+	GridSize   int
+	CustomData map[int]string   // tile id to custom data
+	Tags       map[string][]int // enum value to tile ids
+}
+
+// LDtkLevel is one level
+// Layers are in drawing order, the bottom one first.
+type LDtkLevel struct {
+	Identifier      string
+	IID             string
+	UID             int
+	World           string // world identifier in multi-world projects
+	WorldX, WorldY  int
+	WorldDepth      int
+	Width, Height   int // pixels
+	BackgroundColor Color
+	Fields          LDtkFields
+	Layers          []*LDtkLayer
+	Neighbours      []LDtkNeighbour
+
+	background    *Image
+	backgroundSrc Rectangle
+	backgroundDst Rectangle
+}
+
+// LDtkNeighbour is a level touching another one
+type LDtkNeighbour struct {
+	LevelIID string
+	Dir      string // n, s, e, w, or < > for depth
+}
+
+// LDtkLayer is one layer of a level
+// IntGrid layers keep their values for collision, tile layers and
+// auto-layers their tiles, entity layers their entities.
+type LDtkLayer struct {
+	Identifier    string
+	IID           string
+	Type          string // IntGrid, Entities, Tiles or AutoLayer
+	GridSize      int
+	Width, Height int     // cells
+	Offset        Vector2 // pixels from the level's top-left
+	Opacity       float32
+	Visible       bool
+	IntGrid       []int // Width*Height values, 0 is empty
+	Tiles         []LDtkTile
+	Tileset       *LDtkTileset
+	Entities      []*LDtkEntity
+
+	// Vertices of the tiles, built on the first draw
+	vertices []float32
+	indices  []uint32
+}
+
+// LDtkTile is one tile of a tile or auto-layer
+type LDtkTile struct {
+	ID    int
+	X, Y  float32   // pixels in the layer
+	Src   Rectangle // area in the tileset image
+	Flags uint32    // TileFlipX and TileFlipY
+	Alpha float32
+}
+
+// LDtkEntity is an entity instance
+// X and Y are where its pivot is in the level, Bounds gives its box.
+type LDtkEntity struct {
+	Identifier     string
+	IID            string
+	X, Y           float32
+	GridX, GridY   int
+	WorldX, WorldY float32
+	Width, Height  float32
+	Pivot          Vector2 // 0 to 1 of the size
+	Tags           []string
+	Fields         LDtkFields
+	Tileset        *LDtkTileset // editor tile, nil if none
+	TileSrc        Rectangle
+}
+
+// LDtkEntityRef is the value of an entity reference field
+type LDtkEntityRef struct {
+	EntityIID, LayerIID, LevelIID, WorldIID string
+}
+
+// LDtkFields are the custom fields of a level or entity
+// Values are int, float64, string (also enums and file paths), bool,
+// Color, Vector2 grid cells for points, LDtkEntityRef, Rectangle tileset
+// areas for tiles, and []any for arrays.
+type LDtkFields map[string]any
+
+// String returns a string field, "" if missing or null
+func (f LDtkFields) String(name string) string {
+	s, _ := f[name].(string)
+	return s
+}
+
+// Int returns an int field, 0 if missing or null
+func (f LDtkFields) Int(name string) int {
+	switch v := f[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// Float returns a float field, 0 if missing or null
+func (f LDtkFields) Float(name string) float64 {
+	switch v := f[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// Bool returns a bool field, false if missing or null
+func (f LDtkFields) Bool(name string) bool {
+	b, _ := f[name].(bool)
+	return b
+}
+
+// LoadLDtk loads an .ldtk project, its external level files and tileset images
+func LoadLDtk(path string) (*LDtkProject, error) {
+	var doc ldtkProjectDoc
+	if err := readLDtkFile(path, &doc); err != nil {
+		return nil, err
+	}
+	p := &LDtkProject{
+		WorldLayout:     doc.WorldLayout,
+		WorldGridWidth:  doc.WorldGridWidth,
+		WorldGridHeight: doc.WorldGridHeight,
+		BackgroundColor: parseLDtkColor(doc.BgColor),
+	}
+	if err := p.load(filepath.Dir(path), &doc); err != nil {
+		p.Delete()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *LDtkProject) load(dir string, doc *ldtkProjectDoc) error {
+	tilesets := make(map[int]*LDtkTileset)
+	for _, def := range doc.Defs.Tilesets {
+		ts := &LDtkTileset{
+			UID:        def.UID,
+			Identifier: def.Identifier,
+			GridSize:   def.TileGridSize,
+			CustomData: make(map[int]string),
+			Tags:       make(map[string][]int),
+		}
+		if def.RelPath != nil && *def.RelPath != "" {
+			img, err := LoadImage(filepath.Join(dir, *def.RelPath))
+			if err != nil {
+				return err
+			}
+			ts.Image = img
+			p.images = append(p.images, img)
+		}
+		for _, c := range def.CustomData {
+			ts.CustomData[c.TileID] = c.Data
+		}
+		for _, t := range def.EnumTags {
+			ts.Tags[t.EnumValueID] = t.TileIDs
+		}
+		tilesets[ts.UID] = ts
+		p.Tilesets = append(p.Tilesets, ts)
+	}
+
+	// Multi-world projects keep their levels in worlds
+	type worldLevels struct {
+		name   string
+		layout string
+		levels []ldtkLevelDoc
+	}
+	worlds := []worldLevels{{"", doc.WorldLayout, doc.Levels}}
+	for _, w := range doc.Worlds {
+		worlds = append(worlds, worldLevels{w.Identifier, w.WorldLayout, w.Levels})
+	}
+
+	for _, w := range worlds {
+		var nextX, nextY int
+		for _, ld := range w.levels {
+			if ld.ExternalRelPath != nil && *ld.ExternalRelPath != "" {
+				// The level file has everything, the project only a summary
+				var external ldtkLevelDoc
+				if err := readLDtkFile(filepath.Join(dir, *ld.ExternalRelPath), &external); err != nil {
+					return err
+				}
+				ld = external
+			}
+			level, err := p.newLevel(dir, ld, tilesets)
+			if err != nil {
+				return fmt.Errorf("level %q: %w", ld.Identifier, err)
+			}
+			level.World = w.name
+
+			// Linear layouts leave the positions to the reader
+			switch w.layout {
+			case "LinearHorizontal":
+				level.WorldX, level.WorldY = nextX, 0
+				nextX += level.Width
+			case "LinearVertical":
+				level.WorldX, level.WorldY = 0, nextY
+				nextY += level.Height
+			}
+			p.Levels = append(p.Levels, level)
+		}
+	}
+	return nil
+}
+
+func (p *LDtkProject) newLevel(dir string, ld ldtkLevelDoc, tilesets map[int]*LDtkTileset) (*LDtkLevel, error) {
+	level := &LDtkLevel{
+		Identifier:      ld.Identifier,
+		IID:             ld.IID,
+		UID:             ld.UID,
+		WorldX:          ld.WorldX,
+		WorldY:          ld.WorldY,
+		WorldDepth:      ld.WorldDepth,
+		Width:           ld.PxWid,
+		Height:          ld.PxHei,
+		BackgroundColor: parseLDtkColor(ld.BgColor),
+		Fields:          convertLDtkFields(ld.FieldInstances),
+	}
+	for _, n := range ld.Neighbours {
+		level.Neighbours = append(level.Neighbours, LDtkNeighbour{n.LevelIID, n.Dir})
+	}
+
+	if ld.BgRelPath != nil && *ld.BgRelPath != "" && ld.BgPos != nil {
+		img, err := LoadImage(filepath.Join(dir, *ld.BgRelPath))
+		if err != nil {
+			return nil, err
+		}
+		p.images = append(p.images, img)
+		crop, scale, pos := ld.BgPos.CropRect, ld.BgPos.Scale, ld.BgPos.TopLeftPx
+		level.background = img
+		level.backgroundSrc = Rect(crop[0], crop[1], crop[2], crop[3])
+		level.backgroundDst = Rect(pos[0], pos[1], crop[2]*scale[0], crop[3]*scale[1])
+	}
+
+	// LDtk lists layers top first
+	for i := len(ld.LayerInstances) - 1; i >= 0; i-- {
+		li := ld.LayerInstances[i]
+		layer := &LDtkLayer{
+			Identifier: li.Identifier,
+			IID:        li.IID,
+			Type:       li.Type,
+			GridSize:   li.GridSize,
+			Width:      li.CWid,
+			Height:     li.CHei,
+			Offset:     Vec2(li.PxTotalOffsetX, li.PxTotalOffsetY),
+			Opacity:    li.Opacity,
+			Visible:    li.Visible,
+			IntGrid:    li.IntGridCsv,
+		}
+		if li.TilesetDefUID != nil {
+			layer.Tileset = tilesets[*li.TilesetDefUID]
+		}
+		// Tiles are cut at the tileset's grid, which can differ from the layer's
+		tileSize := li.GridSize
+		if layer.Tileset != nil && layer.Tileset.GridSize > 0 {
+			tileSize = layer.Tileset.GridSize
+		}
+		for _, t := range slices.Concat(li.GridTiles, li.AutoLayerTiles) {
+			layer.Tiles = append(layer.Tiles, t.convert(tileSize))
+		}
+		for _, e := range li.EntityInstances {
+			layer.Entities = append(layer.Entities, e.convert(tilesets))
+		}
+		level.Layers = append(level.Layers, layer)
+	}
+	return level, nil
+}
+
+// Level returns the level with an identifier, nil if there is none
+func (p *LDtkProject) Level(identifier string) *LDtkLevel {
+	for _, level := range p.Levels {
+		if level.Identifier == identifier {
+			return level
+		}
+	}
+	return nil
+}
+
+// LevelByIID returns the level with an instance id, nil if there is none
+func (p *LDtkProject) LevelByIID(iid string) *LDtkLevel {
+	for _, level := range p.Levels {
+		if level.IID == iid {
+			return level
+		}
+	}
+	return nil
+}
+
+// LevelAt returns the level under a world point at a depth, nil if there is none
+func (p *LDtkProject) LevelAt(x, y float32, depth int) *LDtkLevel {
+	for _, level := range p.Levels {
+		if level.WorldDepth == depth && level.Bounds().Contains(x, y) {
+			return level
+		}
+	}
+	return nil
+}
+
+// EntityByIID returns the entity with an instance id from any level, nil if there is none
+func (p *LDtkProject) EntityByIID(iid string) *LDtkEntity {
+	for _, level := range p.Levels {
+		for _, layer := range level.Layers {
+			for _, e := range layer.Entities {
+				if e.IID == iid {
+					return e
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Draw draws the levels of a depth that are in view, at their world positions
+func (p *LDtkProject) Draw(depth int) {
+	minX, minY, maxX, maxY := surfaceBounds(viewMatrix.Mul(modelMatrix).Invert())
+	for _, level := range p.Levels {
+		b := level.Bounds()
+		if level.WorldDepth != depth || b.X > maxX || b.Y > maxY || b.X+b.Width < minX || b.Y+b.Height < minY {
+			continue
+		}
+		level.Draw()
+	}
+}
+
+// Delete frees the tileset and background images
+func (p *LDtkProject) Delete() {
+	for _, img := range p.images {
+		img.Delete()
+	}
+	p.images = nil
+}
+
+// Bounds returns the level's area in world pixels
+func (lv *LDtkLevel) Bounds() Rectangle {
+	return Rect(float32(lv.WorldX), float32(lv.WorldY), float32(lv.Width), float32(lv.Height))
+}
+
+// Layer returns the layer with an identifier, nil if there is none
+func (lv *LDtkLevel) Layer(identifier string) *LDtkLayer {
+	for _, layer := range lv.Layers {
+		if layer.Identifier == identifier {
+			return layer
+		}
+	}
+	return nil
+}
+
+// Entities returns every entity with an identifier, from all layers
+func (lv *LDtkLevel) Entities(identifier string) []*LDtkEntity {
+	var entities []*LDtkEntity
+	for _, layer := range lv.Layers {
+		for _, e := range layer.Entities {
+			if e.Identifier == identifier {
+				entities = append(entities, e)
+			}
+		}
+	}
+	return entities
+}
+
+// Draw draws the level's background and tiles at its world position
+// Entities are left to the game.
+func (lv *LDtkLevel) Draw() {
+	PushTransform()
+	Translate(float32(lv.WorldX), float32(lv.WorldY))
+	if lv.BackgroundColor.A > 0 {
+		DrawRectangle(0, 0, float32(lv.Width), float32(lv.Height), lv.BackgroundColor)
+	}
+	if lv.background != nil {
+		DrawImageRegion(lv.background, lv.backgroundSrc, lv.backgroundDst, WHITE)
+	}
+	for _, layer := range lv.Layers {
+		layer.Draw()
+	}
+	PopTransform()
+}
+
+// Draw draws the layer's tiles, relative to the level's top-left corner
+func (l *LDtkLayer) Draw() {
+	if !l.Visible || l.Opacity <= 0 || l.Tileset == nil || l.Tileset.Image == nil || len(l.Tiles) == 0 {
+		return
+	}
+	if l.indices == nil {
+		for _, t := range l.Tiles {
+			dst := Rect(t.X, t.Y, t.Src.Width, t.Src.Height)
+			tint := Color{1, 1, 1, t.Alpha * l.Opacity}
+			l.vertices, l.indices = appendTileQuad(l.vertices, l.indices, l.Tileset.Image, t.Src, t.Flags, dst, tint)
+		}
+	}
+	// The layer offset and current transform go in the draw state
+	state := currentState(TextureVertex, Triangles, l.Tileset.Image.TextureID)
+	state.transform = state.transform.Mul(modelMatrix).Mul(MatrixTranslate(l.Offset.X, l.Offset.Y))
+	batchAppend(state, l.vertices, l.indices)
+}
+
+// Invalidate rebuilds the layer's vertices on the next draw, call it after changing Tiles or Opacity
+func (l *LDtkLayer) Invalidate() {
+	l.vertices, l.indices = nil, nil
+}
+
+// IntAt returns the IntGrid value of a cell, 0 outside the layer
+func (l *LDtkLayer) IntAt(cx, cy int) int {
+	if cx < 0 || cy < 0 || cx >= l.Width || cy >= l.Height || cy*l.Width+cx >= len(l.IntGrid) {
+		return 0
+	}
+	return l.IntGrid[cy*l.Width+cx]
+}
+
+// IntAtPoint returns the IntGrid value under a point in level pixels
+func (l *LDtkLayer) IntAtPoint(x, y float32) int {
+	if l.GridSize <= 0 {
+		return 0
+	}
+	size := float32(l.GridSize)
+	return l.IntAt(floorDiv(x-l.Offset.X, size), floorDiv(y-l.Offset.Y, size))
+}
+
+// Bounds returns the entity's box in level pixels
+func (e *LDtkEntity) Bounds() Rectangle {
+	return Rect(e.X-e.Pivot.X*e.Width, e.Y-e.Pivot.Y*e.Height, e.Width, e.Height)
+}
+
+func readLDtkFile(path string, doc any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LDtk colors are #RRGGBB
+func parseLDtkColor(s string) Color {
+	if s == "" {
+		return Color{}
+	}
+	return parseTiledColor(s)
+}
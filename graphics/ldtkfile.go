@@ -0,0 +1,239 @@
+package graphics
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LDtk JSON, only the parts the loader uses
+
+type ldtkProjectDoc struct {
+	WorldLayout     string         `json:"worldLayout"`
+	WorldGridWidth  int            `json:"worldGridWidth"`
+	WorldGridHeight int            `json:"worldGridHeight"`
+	BgColor         string         `json:"bgColor"`
+	Defs            ldtkDefsDoc    `json:"defs"`
+	Levels          []ldtkLevelDoc `json:"levels"`
+	Worlds          []struct {
+		Identifier  string         `json:"identifier"`
+		WorldLayout string         `json:"worldLayout"`
+		Levels      []ldtkLevelDoc `json:"levels"`
+	} `json:"worlds"`
+}
+
+type ldtkDefsDoc struct {
+	Tilesets []struct {
+		UID          int     `json:"uid"`
+		Identifier   string  `json:"identifier"`
+		RelPath      *string `json:"relPath"`
+		TileGridSize int     `json:"tileGridSize"`
+		CustomData   []struct {
+			TileID int    `json:"tileId"`
+			Data   string `json:"data"`
+		} `json:"customData"`
+		EnumTags []struct {
+			EnumValueID string `json:"enumValueId"`
+			TileIDs     []int  `json:"tileIds"`
+		} `json:"enumTags"`
+	} `json:"tilesets"`
+}
+
+type ldtkLevelDoc struct {
+	Identifier string  `json:"identifier"`
+	IID        string  `json:"iid"`
+	UID        int     `json:"uid"`
+	WorldX     int     `json:"worldX"`
+	WorldY     int     `json:"worldY"`
+	WorldDepth int     `json:"worldDepth"`
+	PxWid      int     `json:"pxWid"`
+	PxHei      int     `json:"pxHei"`
+	BgColor    string  `json:"__bgColor"`
+	BgRelPath  *string `json:"bgRelPath"`
+	BgPos      *struct {
+		TopLeftPx [2]float32 `json:"topLeftPx"`
+		Scale     [2]float32 `json:"scale"`
+		CropRect  [4]float32 `json:"cropRect"`
+	} `json:"__bgPos"`
+	ExternalRelPath *string        `json:"externalRelPath"`
+	FieldInstances  []ldtkFieldDoc `json:"fieldInstances"`
+	LayerInstances  []ldtkLayerDoc `json:"layerInstances"`
+	Neighbours      []struct {
+		LevelIID string `json:"levelIid"`
+		Dir      string `json:"dir"`
+	} `json:"__neighbours"`
+}
+
+type ldtkLayerDoc struct {
+	Identifier      string          `json:"__identifier"`
+	Type            string          `json:"__type"`
+	IID             string          `json:"iid"`
+	CWid            int             `json:"__cWid"`
+	CHei            int             `json:"__cHei"`
+	GridSize        int             `json:"__gridSize"`
+	Opacity         float32         `json:"__opacity"`
+	PxTotalOffsetX  float32         `json:"__pxTotalOffsetX"`
+	PxTotalOffsetY  float32         `json:"__pxTotalOffsetY"`
+	TilesetDefUID   *int            `json:"__tilesetDefUid"`
+	Visible         bool            `json:"visible"`
+	IntGridCsv      []int           `json:"intGridCsv"`
+	GridTiles       []ldtkTileDoc   `json:"gridTiles"`
+	AutoLayerTiles  []ldtkTileDoc   `json:"autoLayerTiles"`
+	EntityInstances []ldtkEntityDoc `json:"entityInstances"`
+}
+
+type ldtkTileDoc struct {
+	Px  [2]float32 `json:"px"`
+	Src [2]float32 `json:"src"`
+	F   int        `json:"f"` // bit 0 flips x, bit 1 flips y
+	T   int        `json:"t"`
+	A   *float32   `json:"a"`
+}
+
+type ldtkEntityDoc struct {
+	Identifier string         `json:"__identifier"`
+	IID        string         `json:"iid"`
+	Grid       [2]int         `json:"__grid"`
+	Pivot      [2]float32     `json:"__pivot"`
+	Tags       []string       `json:"__tags"`
+	Tile       *ldtkTileRect  `json:"__tile"`
+	WorldX     *float32       `json:"__worldX"`
+	WorldY     *float32       `json:"__worldY"`
+	Width      float32        `json:"width"`
+	Height     float32        `json:"height"`
+	Px         [2]float32     `json:"px"`
+	Fields     []ldtkFieldDoc `json:"fieldInstances"`
+}
+
+type ldtkTileRect struct {
+	TilesetUID int     `json:"tilesetUid"`
+	X          float32 `json:"x"`
+	Y          float32 `json:"y"`
+	W          float32 `json:"w"`
+	H          float32 `json:"h"`
+}
+
+type ldtkFieldDoc struct {
+	Identifier string          `json:"__identifier"`
+	Type       string          `json:"__type"`
+	Value      json.RawMessage `json:"__value"`
+}
+
+func (t ldtkTileDoc) convert(tileSize int) LDtkTile {
+	tile := LDtkTile{
+		ID:    t.T,
+		X:     t.Px[0],
+		Y:     t.Px[1],
+		Src:   Rect(t.Src[0], t.Src[1], float32(tileSize), float32(tileSize)),
+		Alpha: 1,
+	}
+	if t.F&1 != 0 {
+		tile.Flags |= TileFlipX
+	}
+	if t.F&2 != 0 {
+		tile.Flags |= TileFlipY
+	}
+	if t.A != nil {
+		tile.Alpha = *t.A
+	}
+	return tile
+}
+
+func (e ldtkEntityDoc) convert(tilesets map[int]*LDtkTileset) *LDtkEntity {
+	entity := &LDtkEntity{
+		Identifier: e.Identifier,
+		IID:        e.IID,
+		X:          e.Px[0],
+		Y:          e.Px[1],
+		GridX:      e.Grid[0],
+		GridY:      e.Grid[1],
+		Width:      e.Width,
+		Height:     e.Height,
+		Pivot:      Vec2(e.Pivot[0], e.Pivot[1]),
+		Tags:       e.Tags,
+		Fields:     convertLDtkFields(e.Fields),
+	}
+	if e.WorldX != nil && e.WorldY != nil {
+		entity.WorldX, entity.WorldY = *e.WorldX, *e.WorldY
+	}
+	if e.Tile != nil {
+		entity.Tileset = tilesets[e.Tile.TilesetUID]
+		entity.TileSrc = e.Tile.rect()
+	}
+	return entity
+}
+
+func (r ldtkTileRect) rect() Rectangle {
+	return Rect(r.X, r.Y, r.W, r.H)
+}
+
+func convertLDtkFields(docs []ldtkFieldDoc) LDtkFields {
+	fields := make(LDtkFields, len(docs))
+	for _, f := range docs {
+		fields[f.Identifier] = ldtkFieldValue(f.Type, f.Value)
+	}
+	return fields
+}
+
+// Turn a field value into its Go type, null stays nil
+func ldtkFieldValue(kind string, raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if inner, ok := strings.CutPrefix(kind, "Array<"); ok {
+		inner = strings.TrimSuffix(inner, ">")
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return nil
+		}
+		values := make([]any, len(items))
+		for i, item := range items {
+			values[i] = ldtkFieldValue(inner, item)
+		}
+		return values
+	}
+
+	switch kind {
+	case "Int":
+		var v int
+		json.Unmarshal(raw, &v)
+		return v
+	case "Float":
+		var v float64
+		json.Unmarshal(raw, &v)
+		return v
+	case "Bool":
+		var v bool
+		json.Unmarshal(raw, &v)
+		return v
+	case "Color":
+		var v string
+		json.Unmarshal(raw, &v)
+		return parseLDtkColor(v)
+	case "Point":
+		var v struct{ Cx, Cy float32 }
+		json.Unmarshal(raw, &v)
+		return Vec2(v.Cx, v.Cy)
+	case "EntityRef":
+		var v struct {
+			EntityIID string `json:"entityIid"`
+			LayerIID  string `json:"layerIid"`
+			LevelIID  string `json:"levelIid"`
+			WorldIID  string `json:"worldIid"`
+		}
+		json.Unmarshal(raw, &v)
+		return LDtkEntityRef{v.EntityIID, v.LayerIID, v.LevelIID, v.WorldIID}
+	case "Tile":
+		var v ldtkTileRect
+		json.Unmarshal(raw, &v)
+		return v.rect()
+	}
+
+	// Strings, multilines, file paths and enums
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var v any
+	json.Unmarshal(raw, &v)
+	return v
+}
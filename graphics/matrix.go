@@ -0,0 +1,85 @@
+package graphics
+
+import (
+	"math"
+)
+
+// Matrix is a 2D affine transform stored as a 3x3 column-major matrix,
+// the layout OpenGL expects for a mat3 uniform.
+// Angles are in degrees, positive turns counter-clockwise on screen.
+type Matrix [9]float32
+
+// MatrixIdentity returns a transform that changes nothing
+func MatrixIdentity() Matrix {
+	return Matrix{
+		1, 0, 0,
+		0, 1, 0,
+		0, 0, 1,
+	}
+}
+
+// MatrixTranslate returns a transform that moves points by (x, y)
+func MatrixTranslate(x, y float32) Matrix {
+	return Matrix{
+		1, 0, 0,
+		0, 1, 0,
+		x, y, 1,
+	}
+}
+
+// MatrixScale returns a transform that scales around the origin
+func MatrixScale(sx, sy float32) Matrix {
+	return Matrix{
+		sx, 0, 0,
+		0, sy, 0,
+		0, 0, 1,
+	}
+}
+
+// MatrixRotate returns a transform that rotates around the origin
+// Screen Y points down, so the sine terms are flipped to keep
+// positive angles counter-clockwise like DrawImageRotated.
+func MatrixRotate(degrees float32) Matrix {
+	rad := float64(degrees) * math.Pi / 180.0
+	c := float32(math.Cos(rad))
+	s := float32(math.Sin(rad))
+	return Matrix{
+		c, -s, 0,
+		s, c, 0,
+		0, 0, 1,
+	}
+}
+
+// Mul returns m * n, the transform that applies n first and then m
+func (m Matrix) Mul(n Matrix) Matrix {
+	var out Matrix
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			out[col*3+row] = m[0*3+row]*n[col*3+0] + m[1*3+row]*n[col*3+1] + m[2*3+row]*n[col*3+2]
+		}
+	}
+	return out
+}
+
+// Apply transforms the point (x, y)
+func (m Matrix) Apply(x, y float32) (float32, float32) {
+	return m[0]*x + m[3]*y + m[6], m[1]*x + m[4]*y + m[7]
+}
+
+// Invert returns the inverse transform, or the identity if m cannot be inverted
+func (m Matrix) Invert() Matrix {
+	det := m[0]*m[4] - m[3]*m[1]
+	if det == 0 {
+		return MatrixIdentity()
+	}
+	inv := 1 / det
+	a := m[4] * inv
+	b := -m[1] * inv
+	c := -m[3] * inv
+	d := m[0] * inv
+	return Matrix{
+		a, b, 0,
+		c, d, 0,
+		-(a*m[6] + c*m[7]), -(b*m[6] + d*m[7]), 1,
+	}
+}
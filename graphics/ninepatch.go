@@ -0,0 +1,214 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+)
+
+// Insets are distances in from each side of a rectangle
+type Insets struct {
+	Left, Top, Right, Bottom float32
+}
+
+// NinePatchMode is how the edges and center fill their space
+type NinePatchMode int
+
+const (
+	NineStretch NinePatchMode = iota // scale them to fit
+	NineTile                         // repeat them at their own size, cutting the last one short
+)
+
+// NinePatch is an image cut into corners that keep their size,
+// edges that stretch one way and a center that stretches both ways
+type NinePatch struct {
+	Image   *Image
+	Src     Rectangle // area of the image to use, the whole image if empty
+	Insets  Insets    // corner sizes, in image pixels
+	Content Insets    // padding for what goes inside, from .9.png markers
+	Edges   NinePatchMode
+	Center  NinePatchMode
+}
+
+// DrawNinePatch draws an image stretched over a rectangle, keeping corners of the given sizes crisp
+func DrawNinePatch(img *Image, insets Insets, x, y, w, h float32, tint Color) {
+	n := NinePatch{Image: img, Insets: insets}
+	n.Draw(x, y, w, h, tint)
+}
+
+// LoadNinePatch loads an Android style .9.png
+// The 1 pixel border holds black markers: top and left mark the stretchy
+// part, bottom and right the content area. Only the span from the first
+// to the last marker pixel on each side is used.
+func LoadNinePatch(filePath string) (*NinePatch, error) {
+	img, err := decodeImageFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	n, err := NewNinePatchFromImage(img)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return n, nil
+}
+
+// NewNinePatchFromImage reads .9.png markers from a decoded image and uploads the rest
+func NewNinePatchFromImage(img image.Image) (*NinePatch, error) {
+	b := img.Bounds()
+	if b.Dx() < 3 || b.Dy() < 3 {
+		return nil, fmt.Errorf("nine-patch: image is too small for a marker border")
+	}
+	w, h := b.Dx()-2, b.Dy()-2
+
+	// Marker spans in inner pixels
+	marks := func(x, y, dx, dy, n int) (int, int, bool) {
+		first, last := -1, -1
+		for i := 0; i < n; i++ {
+			if isNinePatchMarker(img.At(x+dx*i, y+dy*i)) {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+		return first, last + 1, first >= 0
+	}
+
+	left, right, ok := marks(b.Min.X+1, b.Min.Y, 1, 0, w)
+	if !ok {
+		return nil, fmt.Errorf("nine-patch: no stretch marker on the top border")
+	}
+	top, bottom, ok := marks(b.Min.X, b.Min.Y+1, 0, 1, h)
+	if !ok {
+		return nil, fmt.Errorf("nine-patch: no stretch marker on the left border")
+	}
+	n := &NinePatch{
+		Insets: Insets{float32(left), float32(top), float32(w - right), float32(h - bottom)},
+	}
+	// Without content markers the content area is the stretchy part
+	n.Content = n.Insets
+	if cl, cr, ok := marks(b.Min.X+1, b.Max.Y-1, 1, 0, w); ok {
+		n.Content.Left, n.Content.Right = float32(cl), float32(w-cr)
+	}
+	if ct, cb, ok := marks(b.Max.X-1, b.Min.Y+1, 0, 1, h); ok {
+		n.Content.Top, n.Content.Bottom = float32(ct), float32(h-cb)
+	}
+
+	inner := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			inner.Set(x, y, img.At(b.Min.X+1+x, b.Min.Y+1+y))
+		}
+	}
+	n.Image = NewImageFromImage(inner)
+	return n, nil
+}
+
+// Markers are opaque black, anti-aliased borders count if mostly so
+func isNinePatchMarker(c interface{ RGBA() (r, g, b, a uint32) }) bool {
+	r, g, b, a := c.RGBA()
+	return a >= 0x8000 && r < 0x4000 && g < 0x4000 && b < 0x4000
+}
+
+// ContentRect returns the area inside the content padding when drawn at (x, y, w, h)
+func (n *NinePatch) ContentRect(x, y, w, h float32) Rectangle {
+	return Rect(x+n.Content.Left, y+n.Content.Top,
+		w-n.Content.Left-n.Content.Right, h-n.Content.Top-n.Content.Bottom)
+}
+
+// Draw draws the nine-patch over a rectangle
+// All nine parts go out together as one batch of quads.
+func (n *NinePatch) Draw(x, y, w, h float32, tint Color) {
+	img := n.Image
+	if img == nil || img.TextureID == 0 || w <= 0 || h <= 0 {
+		return
+	}
+	if tint == (Color{}) {
+		tint = WHITE
+	}
+	src := n.Src
+	if src.Width <= 0 || src.Height <= 0 {
+		src = Rect(0, 0, float32(img.Width), float32(img.Height))
+	}
+
+	// Corners shrink evenly when the rectangle is smaller than them
+	in := n.Insets
+	sx := min(1, w/max(in.Left+in.Right, 1e-6))
+	sy := min(1, h/max(in.Top+in.Bottom, 1e-6))
+
+	// Column and row edges, in the image and on screen
+	srcX := [4]float32{src.X, src.X + in.Left, src.X + src.Width - in.Right, src.X + src.Width}
+	srcY := [4]float32{src.Y, src.Y + in.Top, src.Y + src.Height - in.Bottom, src.Y + src.Height}
+	dstX := [4]float32{x, x + in.Left*sx, x + w - in.Right*sx, x + w}
+	dstY := [4]float32{y, y + in.Top*sy, y + h - in.Bottom*sy, y + h}
+
+	var vertices []float32
+	var indices []uint32
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			s := Rect(srcX[col], srcY[row], srcX[col+1]-srcX[col], srcY[row+1]-srcY[row])
+			d := Rect(dstX[col], dstY[row], dstX[col+1]-dstX[col], dstY[row+1]-dstY[row])
+			if s.Width <= 0 || s.Height <= 0 || d.Width <= 0 || d.Height <= 0 {
+				continue
+			}
+			mode := n.Edges
+			if row == 1 && col == 1 {
+				mode = n.Center
+			}
+			// Corners and the fixed direction of edges are never tiled
+			tileX := mode == NineTile && col == 1
+			tileY := mode == NineTile && row == 1
+			vertices, indices = appendNinePatchPart(vertices, indices, img, s, d, tileX, tileY, tint)
+		}
+	}
+	if len(indices) > 0 {
+		drawTexturedQuad(img, vertices, indices)
+	}
+}
+
+// Add one part, as a single quad or repeated tiles
+func appendNinePatchPart(vertices []float32, indices []uint32, img *Image, s, d Rectangle, tileX, tileY bool, tint Color) ([]float32, []uint32) {
+	stepX, stepY := d.Width, d.Height
+	if tileX {
+		stepX = s.Width
+	}
+	if tileY {
+		stepY = s.Height
+	}
+	for ty := float32(0); ty < d.Height; ty += stepY {
+		th := min(stepY, d.Height-ty)
+		for tx := float32(0); tx < d.Width; tx += stepX {
+			tw := min(stepX, d.Width-tx)
+			// Tiles cut short use the matching part of the source
+			part := s
+			if tileX {
+				part.Width = tw
+			}
+			if tileY {
+				part.Height = th
+			}
+			vertices, indices = appendTexturedQuad(vertices, indices, img, part, Rect(d.X+tx, d.Y+ty, tw, th), tint)
+		}
+	}
+	return vertices, indices
+}
+
+// Add a quad showing src, a top-left based image rectangle, at dst
+func appendTexturedQuad(vertices []float32, indices []uint32, img *Image, src, dst Rectangle, tint Color) ([]float32, []uint32) {
+	iw, ih := float32(img.Width), float32(img.Height)
+	u0, u1 := src.X/iw, (src.X+src.Width)/iw
+	v0, v1 := 1-src.Y/ih, 1-(src.Y+src.Height)/ih // rows are stored bottom-up
+
+	base := uint32(len(vertices) / 8)
+	corners := [4][4]float32{
+		{dst.X, dst.Y, u0, v0},
+		{dst.X + dst.Width, dst.Y, u1, v0},
+		{dst.X + dst.Width, dst.Y + dst.Height, u1, v1},
+		{dst.X, dst.Y + dst.Height, u0, v1},
+	}
+	for _, c := range corners {
+		px, py := transformPoint(c[0], c[1])
+		vertices = append(vertices, px, py, c[2], c[3], tint.R, tint.G, tint.B, tint.A)
+	}
+	indices = append(indices, base, base+1, base+2, base+2, base+3, base)
+	return vertices, indices
+}
@@ -0,0 +1,461 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// ParticleShape is what particles look like without an image
+type ParticleShape int
+
+const (
+	ParticleSquare ParticleShape = iota
+	ParticleCircle
+)
+
+// Segments of a circle particle, they are small so a few are enough
+const particleCircleSegments = 12
+
+var particleShapeNames = map[ParticleShape]string{
+	ParticleSquare: "square",
+	ParticleCircle: "circle",
+}
+
+// MarshalText writes the shape by name
+func (s ParticleShape) MarshalText() ([]byte, error) {
+	if _, ok := particleShapeNames[s]; !ok {
+		return nil, fmt.Errorf("unknown particle shape %d", int(s))
+	}
+	return []byte(particleShapeNames[s]), nil
+}
+
+// UnmarshalText reads a shape by name
+func (s *ParticleShape) UnmarshalText(text []byte) error {
+	for shape, name := range particleShapeNames {
+		if name == string(text) {
+			*s = shape
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown particle shape %q", text)
+}
+
+// ParticleRange is a value picked at random between Min and Max
+type ParticleRange struct {
+	Min float32 `json:"min"`
+	Max float32 `json:"max"`
+}
+
+func (r ParticleRange) random() float32 {
+	return r.Min + rand.Float32()*(r.Max-r.Min)
+}
+
+// CurveKey is a value at a point of a particle's life, T goes from 0 to 1
+type CurveKey struct {
+	T     float32 `json:"t"`
+	Value float32 `json:"value"`
+}
+
+// ColorKey is a color at a point of a particle's life, T goes from 0 to 1
+type ColorKey struct {
+	T     float32 `json:"t"`
+	Color Color   `json:"color"`
+}
+
+// ParticleBurst emits Count particles at once, Time seconds into the emission
+// Repeat fires it that many more times, Interval apart, -1 repeats forever.
+type ParticleBurst struct {
+	Time     float32 `json:"time"`
+	Count    int     `json:"count"`
+	Repeat   int     `json:"repeat,omitempty"`
+	Interval float32 `json:"interval,omitempty"`
+}
+
+// ParticleConfig describes an effect, it is what gets saved to JSON
+// Angles are in degrees like DrawOptions.Rotation, keys in order of T.
+// Particles only appear with a Rate, Bursts or calls to Burst. A zero
+// Lifetime or Size is filled in by NewParticleEmitter, a zero Speed is
+// kept and leaves particles where they spawn.
+type ParticleConfig struct {
+	Image string        `json:"image,omitempty"` // texture path, relative to the JSON file
+	Src   Rectangle     `json:"src"`             // area of the image, all of it when empty
+	Shape ParticleShape `json:"shape"`           // used when there is no image
+	Blend BlendMode     `json:"blend"`
+
+	Rate         float32         `json:"rate"` // particles per second
+	Bursts       []ParticleBurst `json:"bursts,omitempty"`
+	Duration     float32         `json:"duration"` // seconds of emission, 0 for forever
+	Loop         bool            `json:"loop"`     // start again after Duration
+	MaxParticles int             `json:"maxParticles"`
+	SpawnArea    Vector2         `json:"spawnArea"` // size of the box around Position particles start in
+
+	Lifetime  ParticleRange `json:"lifetime"` // seconds
+	Speed     ParticleRange `json:"speed"`    // pixels per second
+	Direction float32       `json:"direction"`
+	Spread    float32       `json:"spread"` // degrees to either side of Direction
+	Gravity   Vector2       `json:"gravity"`
+	Drag      float32       `json:"drag"` // fraction of the speed lost per second
+
+	Size          ParticleRange `json:"size"` // pixels
+	SizeOverLife  []CurveKey    `json:"sizeOverLife,omitempty"`
+	ColorOverLife []ColorKey    `json:"colorOverLife,omitempty"`
+	Rotation      ParticleRange `json:"rotation"`
+	Spin          ParticleRange `json:"spin"` // degrees per second
+}
+
+// LoadParticleConfig reads an effect from a JSON file
+func LoadParticleConfig(path string) (ParticleConfig, error) {
+	var config ParticleConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Save writes the effect to a JSON file
+func (c ParticleConfig) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ParticleEmitter spawns, moves and draws particles
+// Particles live in world space, moving Position does not drag them along.
+type ParticleEmitter struct {
+	ParticleConfig
+	Position Vector2
+	Texture  *Image // nil draws Shape
+
+	particles []particle
+	emitting  bool
+	time      float32 // seconds into the emission
+	pending   float32 // fraction of a particle left over from Rate
+	ownsImage bool
+
+	// Reused every draw
+	vertices []float32
+	indices  []uint32
+}
+
+type particle struct {
+	pos, vel       Vector2
+	rotation, spin float32
+	size           float32
+	age, life      float32
+}
+
+// Used for a zero Lifetime or Size
+var (
+	defaultParticleLifetime = ParticleRange{1, 1}
+	defaultParticleSize     = ParticleRange{8, 8}
+)
+
+// NewParticleEmitter creates an emitter that starts emitting right away
+// A zero Lifetime becomes one second and a zero Size 8 pixels.
+func NewParticleEmitter(config ParticleConfig, img *Image) *ParticleEmitter {
+	if config.Lifetime == (ParticleRange{}) {
+		config.Lifetime = defaultParticleLifetime
+	}
+	if config.Size == (ParticleRange{}) {
+		config.Size = defaultParticleSize
+	}
+	return &ParticleEmitter{ParticleConfig: config, Texture: img, emitting: true}
+}
+
+// LoadParticleEmitter creates an emitter from a JSON file, loading its texture
+func LoadParticleEmitter(path string) (*ParticleEmitter, error) {
+	config, err := LoadParticleConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	e := NewParticleEmitter(config, nil)
+	if config.Image != "" {
+		img, err := LoadImage(filepath.Join(filepath.Dir(path), config.Image))
+		if err != nil {
+			return nil, err
+		}
+		e.Texture, e.ownsImage = img, true
+	}
+	return e, nil
+}
+
+// Start restarts the emission from the beginning, live particles stay
+func (e *ParticleEmitter) Start() {
+	e.emitting = true
+	e.time, e.pending = 0, 0
+}
+
+// Stop ends the emission, live particles finish their lives
+func (e *ParticleEmitter) Stop() {
+	e.emitting = false
+}
+
+// Emitting reports whether new particles are being spawned
+func (e *ParticleEmitter) Emitting() bool {
+	return e.emitting
+}
+
+// IsFinished reports whether the emission is over and every particle is gone
+func (e *ParticleEmitter) IsFinished() bool {
+	return !e.emitting && len(e.particles) == 0
+}
+
+// Count returns the number of live particles
+func (e *ParticleEmitter) Count() int {
+	return len(e.particles)
+}
+
+// Clear removes every live particle
+func (e *ParticleEmitter) Clear() {
+	e.particles = e.particles[:0]
+}
+
+// Burst spawns count particles now
+func (e *ParticleEmitter) Burst(count int) {
+	for range count {
+		e.spawn()
+	}
+}
+
+// Update advances the emitter by the frame's delta time
+func (e *ParticleEmitter) Update() {
+	e.UpdateBy(GetDeltaTime())
+}
+
+// UpdateBy advances the emitter by dt seconds
+func (e *ParticleEmitter) UpdateBy(dt float64) {
+	step := float32(dt)
+	if step <= 0 {
+		return
+	}
+
+	// Move the particles, keeping their order so overlaps don't flicker
+	drag := max(0, 1-e.Drag*step)
+	alive := e.particles[:0]
+	for _, p := range e.particles {
+		p.age += step
+		if p.age >= p.life {
+			continue
+		}
+		p.vel = p.vel.Add(e.Gravity.Scale(step)).Scale(drag)
+		p.pos = p.pos.Add(p.vel.Scale(step))
+		p.rotation += p.spin * step
+		alive = append(alive, p)
+	}
+	e.particles = alive
+
+	// Emit, one cycle of Duration at a time
+	for step > 0 && e.emitting {
+		span := step
+		if e.Duration > 0 {
+			span = min(step, e.Duration-e.time)
+		}
+		e.emit(e.time, e.time+span)
+		e.time += span
+		step -= span
+		if e.Duration > 0 && e.time >= e.Duration {
+			if !e.Loop {
+				e.emitting = false
+			}
+			e.time, e.pending = 0, 0
+		}
+	}
+}
+
+// Spawn the particles due between two times of the emission
+func (e *ParticleEmitter) emit(from, to float32) {
+	e.pending += e.Rate * (to - from)
+	for ; e.pending >= 1; e.pending-- {
+		e.spawn()
+	}
+
+	for _, b := range e.Bursts {
+		if b.Interval <= 0 {
+			if b.Time >= from && b.Time < to {
+				e.Burst(b.Count)
+			}
+			continue
+		}
+		// First repetition at or after from
+		k := max(0, int(math.Ceil(float64((from-b.Time)/b.Interval))))
+		for ; b.Repeat < 0 || k <= b.Repeat; k++ {
+			t := b.Time + float32(k)*b.Interval
+			if t >= to {
+				break
+			}
+			if t >= from {
+				e.Burst(b.Count)
+			}
+		}
+	}
+}
+
+func (e *ParticleEmitter) spawn() {
+	if e.MaxParticles > 0 && len(e.particles) >= e.MaxParticles {
+		return
+	}
+	life := e.Lifetime.random()
+	if life <= 0 {
+		return
+	}
+	angle := e.Direction + (rand.Float32()*2-1)*e.Spread
+	dx, dy := MatrixRotate(angle).Apply(1, 0)
+	speed := e.Speed.random()
+	e.particles = append(e.particles, particle{
+		pos: Vec2(
+			e.Position.X+(rand.Float32()-0.5)*e.SpawnArea.X,
+			e.Position.Y+(rand.Float32()-0.5)*e.SpawnArea.Y,
+		),
+		vel:      Vec2(dx*speed, dy*speed),
+		rotation: e.Rotation.random(),
+		spin:     e.Spin.random(),
+		size:     e.Size.random(),
+		life:     life,
+	})
+}
+
+// Draw draws every particle in one batch, with the emitter's blend mode
+func (e *ParticleEmitter) Draw() {
+	if len(e.particles) == 0 {
+		return
+	}
+	defer SetBlendMode(currentBlendMode)
+	SetBlendMode(e.Blend)
+
+	e.vertices, e.indices = e.vertices[:0], e.indices[:0]
+	if e.Texture != nil {
+		e.appendTextured()
+		drawTexturedQuad(e.Texture, e.vertices, e.indices)
+	} else {
+		e.appendShapes()
+		queueShape(e.vertices, e.indices, Triangles)
+	}
+}
+
+// Quads textured with Src, turned by each particle's rotation
+func (e *ParticleEmitter) appendTextured() {
+	iw, ih := float32(e.Texture.Width), float32(e.Texture.Height)
+	src := e.Src
+	if src.Width <= 0 || src.Height <= 0 {
+		src = Rect(0, 0, iw, ih)
+	}
+	u := [2]float32{src.X / iw, (src.X + src.Width) / iw}
+	v := [2]float32{1 - src.Y/ih, 1 - (src.Y+src.Height)/ih} // rows are stored bottom-up
+
+	// Keep the image's proportions, size is the longest side
+	aspect := src.Width / src.Height
+	for _, p := range e.particles {
+		t := p.age / p.life
+		size := p.size * evalCurve(e.SizeOverLife, t)
+		color := evalColorCurve(e.ColorOverLife, t)
+		hw, hh := size/2, size/2
+		if aspect > 1 {
+			hh /= aspect
+		} else {
+			hw *= aspect
+		}
+		rotate := MatrixRotate(p.rotation)
+		base := uint32(len(e.vertices) / 8)
+		corners := [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+		for _, c := range corners {
+			ox, oy := rotate.Apply(float32(c[0]*2-1)*hw, float32(c[1]*2-1)*hh)
+			x, y := transformPoint(p.pos.X+ox, p.pos.Y+oy)
+			e.vertices = append(e.vertices, x, y, u[c[0]], v[c[1]], color.R, color.G, color.B, color.A)
+		}
+		e.indices = append(e.indices, base, base+1, base+2, base+2, base+3, base)
+	}
+}
+
+// Squares or circle fans of plain color
+func (e *ParticleEmitter) appendShapes() {
+	for _, p := range e.particles {
+		t := p.age / p.life
+		half := p.size * evalCurve(e.SizeOverLife, t) / 2
+		color := evalColorCurve(e.ColorOverLife, t)
+		base := uint32(len(e.vertices) / 6)
+
+		if e.Shape == ParticleCircle {
+			e.vertices = appendShapeVertex(e.vertices, p.pos.X, p.pos.Y, color)
+			for i := range particleCircleSegments {
+				a := float64(i) * 2 * math.Pi / particleCircleSegments
+				e.vertices = appendShapeVertex(e.vertices, p.pos.X+half*float32(math.Cos(a)), p.pos.Y+half*float32(math.Sin(a)), color)
+			}
+			for i := uint32(1); i <= particleCircleSegments; i++ {
+				e.indices = append(e.indices, base, base+i, base+i%particleCircleSegments+1)
+			}
+			continue
+		}
+
+		rotate := MatrixRotate(p.rotation)
+		for _, c := range [4][2]float32{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+			ox, oy := rotate.Apply(c[0]*half, c[1]*half)
+			e.vertices = appendShapeVertex(e.vertices, p.pos.X+ox, p.pos.Y+oy, color)
+		}
+		e.indices = append(e.indices, base, base+1, base+2, base+2, base+3, base)
+	}
+}
+
+// Delete frees the texture if the emitter loaded it
+func (e *ParticleEmitter) Delete() {
+	if e.ownsImage && e.Texture != nil {
+		e.Texture.Delete()
+		e.Texture = nil
+	}
+}
+
+// Value of a curve at t, 1 when there are no keys
+func evalCurve(keys []CurveKey, t float32) float32 {
+	if len(keys) == 0 {
+		return 1
+	}
+	if t <= keys[0].T {
+		return keys[0].Value
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].T {
+			a, b := keys[i-1], keys[i]
+			if b.T <= a.T {
+				return b.Value
+			}
+			f := (t - a.T) / (b.T - a.T)
+			return a.Value + (b.Value-a.Value)*f
+		}
+	}
+	return keys[len(keys)-1].Value
+}
+
+// Color of a gradient at t, white when there are no keys
+func evalColorCurve(keys []ColorKey, t float32) Color {
+	if len(keys) == 0 {
+		return WHITE
+	}
+	if t <= keys[0].T {
+		return keys[0].Color
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].T {
+			a, b := keys[i-1], keys[i]
+			if b.T <= a.T {
+				return b.Color
+			}
+			f := (t - a.T) / (b.T - a.T)
+			return Color{
+				a.Color.R + (b.Color.R-a.Color.R)*f,
+				a.Color.G + (b.Color.G-a.Color.G)*f,
+				a.Color.B + (b.Color.B-a.Color.B)*f,
+				a.Color.A + (b.Color.A-a.Color.A)*f,
+			}
+		}
+	}
+	return keys[len(keys)-1].Color
+}
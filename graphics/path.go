@@ -0,0 +1,451 @@
+package graphics
+
+import (
+	"math"
+	"sort"
+)
+
+// FillRule decides which parts of a path are inside when it is filled
+type FillRule int
+
+const (
+	FillNonZero FillRule = iota // inside where the path winds around a point at all
+	FillEvenOdd                 // inside where a ray crosses the path an odd number of times, makes holes
+)
+
+// Path is a shape made of lines and curves, built with MoveTo, LineTo
+// and the curve methods, then drawn with Stroke or Fill.
+// Curves are turned into lines when drawn, with as many
+// segments as their size on screen needs.
+type Path struct {
+	subpaths []subpath
+	current  Vector2
+}
+
+type subpath struct {
+	start    Vector2
+	segments []pathSegment
+	closed   bool
+}
+
+// pathSegment ends at to, quadratic curves use c1, cubic ones c1 and c2
+type pathSegment struct {
+	kind   segmentKind
+	c1, c2 Vector2
+	to     Vector2
+}
+
+type segmentKind int
+
+const (
+	segmentLine segmentKind = iota
+	segmentQuad
+	segmentCubic
+)
+
+// NewPath creates an empty path
+func NewPath() *Path {
+	return &Path{}
+}
+
+// MoveTo starts a new subpath at (x, y)
+func (p *Path) MoveTo(x, y float32) {
+	p.current = Vector2{x, y}
+	p.subpaths = append(p.subpaths, subpath{start: p.current})
+}
+
+// LineTo adds a straight line to (x, y)
+func (p *Path) LineTo(x, y float32) {
+	p.add(pathSegment{kind: segmentLine, to: Vector2{x, y}})
+}
+
+// QuadTo adds a quadratic Bezier curve to (x, y) bending towards (cx, cy)
+func (p *Path) QuadTo(cx, cy, x, y float32) {
+	p.add(pathSegment{kind: segmentQuad, c1: Vector2{cx, cy}, to: Vector2{x, y}})
+}
+
+// CubicTo adds a cubic Bezier curve to (x, y) with two control points
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float32) {
+	p.add(pathSegment{kind: segmentCubic, c1: Vector2{c1x, c1y}, c2: Vector2{c2x, c2y}, to: Vector2{x, y}})
+}
+
+// ArcTo rounds the corner at (x1, y1) between the current point and
+// (x2, y2) with a circle of the given radius, like canvas arcTo.
+// A line goes from the current point to where the arc starts.
+func (p *Path) ArcTo(x1, y1, x2, y2, radius float32) {
+	p0, p1, p2 := p.current, Vector2{x1, y1}, Vector2{x2, y2}
+	if len(p.subpaths) == 0 {
+		p.MoveTo(x1, y1)
+		return
+	}
+	d0, d1 := p0.Sub(p1).Normalize(), p2.Sub(p1).Normalize()
+	cross := d0.Cross(d1)
+	if radius <= 0 || abs32(cross) < 1e-6 {
+		p.LineTo(x1, y1)
+		return
+	}
+
+	// Distance from the corner to where the circle touches each line
+	cosAngle := d0.Dot(d1)
+	halfAngle := float32(math.Acos(float64(max(-1, min(1, cosAngle))))) / 2
+	tangent := radius / float32(math.Tan(float64(halfAngle)))
+	start, end := p1.Add(d0.Scale(tangent)), p1.Add(d1.Scale(tangent))
+
+	// Center sits along the bisector
+	bisector := d0.Add(d1).Normalize()
+	center := p1.Add(bisector.Scale(radius / float32(math.Sin(float64(halfAngle)))))
+
+	p.LineTo(start.X, start.Y)
+	from := angleOf(start.Sub(center))
+	sweep := angleOf(end.Sub(center)) - from
+	if sweep > math.Pi {
+		sweep -= 2 * math.Pi
+	} else if sweep < -math.Pi {
+		sweep += 2 * math.Pi
+	}
+	p.arcCurves(center, radius, from, sweep)
+}
+
+// Arc adds a circular arc around (centerX, centerY) from startAngle to endAngle
+// Angles are in degrees like DrawArc. A line joins the current point
+// to the start of the arc, or a new subpath starts there if there is none.
+func (p *Path) Arc(centerX, centerY, radius, startAngle, endAngle float32) {
+	// Screen angles: y points down, so negate to keep counter-clockwise positive
+	from := -startAngle * math.Pi / 180
+	sweep := -(endAngle - startAngle) * math.Pi / 180
+	center := Vector2{centerX, centerY}
+	start := center.Add(Vector2{float32(math.Cos(float64(from))), float32(math.Sin(float64(from)))}.Scale(radius))
+	if len(p.subpaths) == 0 {
+		p.MoveTo(start.X, start.Y)
+	} else {
+		p.LineTo(start.X, start.Y)
+	}
+	p.arcCurves(center, radius, from, sweep)
+}
+
+// CatmullRomTo adds a smooth curve from the current point through every point
+func (p *Path) CatmullRomTo(points ...Vector2) {
+	if len(points) == 0 {
+		return
+	}
+	if len(p.subpaths) == 0 {
+		p.MoveTo(points[0].X, points[0].Y)
+		points = points[1:]
+	}
+	all := append([]Vector2{p.current}, points...)
+	for i := 0; i+1 < len(all); i++ {
+		p0, p1, p2, p3 := all[max(i-1, 0)], all[i], all[i+1], all[min(i+2, len(all)-1)]
+		// The same curve written as a cubic Bezier
+		c1 := p1.Add(p2.Sub(p0).Scale(1.0 / 6))
+		c2 := p2.Sub(p3.Sub(p1).Scale(1.0 / 6))
+		p.CubicTo(c1.X, c1.Y, c2.X, c2.Y, p2.X, p2.Y)
+	}
+}
+
+// Close joins the end of the current subpath back to its start
+func (p *Path) Close() {
+	if len(p.subpaths) == 0 {
+		return
+	}
+	sub := &p.subpaths[len(p.subpaths)-1]
+	sub.closed = true
+	p.current = sub.start
+	// Drawing on after Close starts from the same point
+	p.subpaths = append(p.subpaths, subpath{start: sub.start})
+}
+
+// Stroke draws the outline of the path
+func (p *Path) Stroke(style StrokeStyle, color Color) {
+	for _, sub := range p.flatten() {
+		strokePoints(sub.points, sub.closed, style, color)
+	}
+}
+
+// Fill draws the inside of the path, open subpaths are closed with a straight line
+func (p *Path) Fill(rule FillRule, color Color) {
+	var polygons [][]Vector2
+	for _, sub := range p.flatten() {
+		polygons = append(polygons, sub.points)
+	}
+	vertices, indices := tessellateFill(polygons, rule, color)
+	if len(indices) > 0 {
+		// The trapezoids bring their own smooth edges
+		queueShape(vertices, indices, Triangles)
+	}
+}
+
+// Add a segment to the last subpath, starting one at the current point if needed
+func (p *Path) add(seg pathSegment) {
+	if len(p.subpaths) == 0 {
+		p.subpaths = append(p.subpaths, subpath{start: p.current})
+	}
+	sub := &p.subpaths[len(p.subpaths)-1]
+	sub.segments = append(sub.segments, seg)
+	p.current = seg.to
+}
+
+// Add an arc as cubic curves of at most a quarter turn each
+// Angles are in radians in screen space (positive is clockwise).
+func (p *Path) arcCurves(center Vector2, radius, from, sweep float32) {
+	parts := int(ceil32(abs32(sweep) / (math.Pi / 2)))
+	step := sweep / float32(max(parts, 1))
+	k := 4.0 / 3 * float32(math.Tan(float64(step/4))) * radius
+	for i := 0; i < parts; i++ {
+		a0 := float64(from + step*float32(i))
+		a1 := a0 + float64(step)
+		cos0, sin0 := float32(math.Cos(a0)), float32(math.Sin(a0))
+		cos1, sin1 := float32(math.Cos(a1)), float32(math.Sin(a1))
+		c1 := Vector2{center.X + radius*cos0 - k*sin0, center.Y + radius*sin0 + k*cos0}
+		c2 := Vector2{center.X + radius*cos1 + k*sin1, center.Y + radius*sin1 - k*cos1}
+		end := Vector2{center.X + radius*cos1, center.Y + radius*sin1}
+		p.CubicTo(c1.X, c1.Y, c2.X, c2.Y, end.X, end.Y)
+	}
+}
+
+// flatPath is a subpath turned into points
+type flatPath struct {
+	points []Vector2
+	closed bool
+}
+
+// Turn curves into lines, sized for the current transform
+func (p *Path) flatten() []flatPath {
+	scale := screenScale()
+	var out []flatPath
+	for _, sub := range p.subpaths {
+		if len(sub.segments) == 0 {
+			continue
+		}
+		points := []Vector2{sub.start}
+		from := sub.start
+		for _, seg := range sub.segments {
+			switch seg.kind {
+			case segmentLine:
+				points = append(points, seg.to)
+			case segmentQuad:
+				m := from.Sub(seg.c1.Scale(2)).Add(seg.to).Length()
+				n := curveSegments(m*scale, 0.25)
+				for i := 1; i <= n; i++ {
+					points = append(points, quadPoint(from, seg.c1, seg.to, float32(i)/float32(n)))
+				}
+			case segmentCubic:
+				m := max(from.Sub(seg.c1.Scale(2)).Add(seg.c2).Length(), seg.c1.Sub(seg.c2.Scale(2)).Add(seg.to).Length())
+				n := curveSegments(m*scale, 0.75)
+				for i := 1; i <= n; i++ {
+					points = append(points, cubicPoint(from, seg.c1, seg.c2, seg.to, float32(i)/float32(n)))
+				}
+			}
+			from = seg.to
+		}
+		out = append(out, flatPath{points: points, closed: sub.closed})
+	}
+	return out
+}
+
+// Segments a Bezier curve needs to stay within a quarter pixel (Wang's formula)
+// m is the largest second difference of the control points on screen,
+// factor is degree*(degree-1)/8.
+func curveSegments(m, factor float32) int {
+	const tolerance = 0.25
+	n := int(ceil32(float32(math.Sqrt(float64(factor * m / tolerance)))))
+	return min(max(n, 1), 512)
+}
+
+func quadPoint(p0, c, p1 Vector2, t float32) Vector2 {
+	u := 1 - t
+	return p0.Scale(u * u).Add(c.Scale(2 * u * t)).Add(p1.Scale(t * t))
+}
+
+func cubicPoint(p0, c1, c2, p1 Vector2, t float32) Vector2 {
+	u := 1 - t
+	return p0.Scale(u * u * u).Add(c1.Scale(3 * u * u * t)).Add(c2.Scale(3 * u * t * t)).Add(p1.Scale(t * t * t))
+}
+
+// fillEdge is a polygon edge going down the screen, dir remembers its original direction
+type fillEdge struct {
+	x0, y0, x1, y1 float32
+	dir            int
+}
+
+func (e fillEdge) xAt(y float32) float32 {
+	return e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+}
+
+// Split filled polygons into trapezoids
+// The area is cut into horizontal slabs at every corner and crossing,
+// inside a slab edges don't cross, so spans between them can be
+// filled by following the fill rule from left to right.
+func tessellateFill(polygons [][]Vector2, rule FillRule, color Color) ([]float32, []uint32) {
+	var edges []fillEdge
+	var ys []float32
+	for _, poly := range polygons {
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			ys = append(ys, a.Y)
+			switch {
+			case a.Y < b.Y:
+				edges = append(edges, fillEdge{a.X, a.Y, b.X, b.Y, 1})
+			case a.Y > b.Y:
+				edges = append(edges, fillEdge{b.X, b.Y, a.X, a.Y, -1})
+			}
+		}
+	}
+	if len(edges) < 2 {
+		return nil, nil
+	}
+
+	// Crossings between edges also start new slabs
+	// With the edges sorted by their top, only the ones starting above
+	// an edge's bottom can overlap it, which keeps long outlines cheap.
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+	for i := range edges {
+		for j := i + 1; j < len(edges) && edges[j].y0 < edges[i].y1; j++ {
+			if y, ok := edgeCrossing(edges[i], edges[j]); ok {
+				ys = append(ys, y)
+			}
+		}
+	}
+	sort.Slice(ys, func(i, j int) bool { return ys[i] < ys[j] })
+
+	type span struct {
+		top, bottom, mid float32
+		dir              int
+	}
+	var vertices []float32
+	var indices []uint32
+	var active []span
+	var live []fillEdge // edges reaching the current slab, added as the sweep goes down
+	next := 0
+	fringe := 1 / max(screenScale(), 1e-6)
+	for k := 0; k+1 < len(ys); k++ {
+		top, bottom := ys[k], ys[k+1]
+		if bottom-top < 1e-6 {
+			continue
+		}
+		for next < len(edges) && edges[next].y0 <= top {
+			live = append(live, edges[next])
+			next++
+		}
+		n := 0
+		for _, e := range live {
+			if e.y1 > top {
+				live[n] = e
+				n++
+			}
+		}
+		live = live[:n]
+
+		mid := (top + bottom) / 2
+		active = active[:0]
+		for _, e := range live {
+			if e.y1 >= bottom {
+				active = append(active, span{e.xAt(top), e.xAt(bottom), e.xAt(mid), e.dir})
+			}
+		}
+		sort.Slice(active, func(i, j int) bool { return active[i].mid < active[j].mid })
+
+		// Spans that touch are merged so only the outline gets a fringe
+		winding, enter := 0, -1
+		for i := range active {
+			if rule == FillEvenOdd {
+				winding++
+			} else {
+				winding += active[i].dir
+			}
+			inside := winding != 0
+			if rule == FillEvenOdd {
+				inside = winding%2 == 1
+			}
+			switch {
+			case inside && enter < 0:
+				enter = i
+			case !inside && enter >= 0:
+				left, right := active[enter], active[i]
+				if !antiAlias {
+					vertices, indices = appendTrapezoid(vertices, indices, left.top, right.top, top, left.bottom, right.bottom, bottom, color)
+					enter = -1
+					continue
+				}
+				// Fade over a pixel centered on the edge, measured across the
+				// slab so flatter edges need a wider fade
+				dl := fringe / 2 * min(sqrt32(1+sq32((left.bottom-left.top)/(bottom-top))), 8)
+				dr := fringe / 2 * min(sqrt32(1+sq32((right.bottom-right.top)/(bottom-top))), 8)
+				clear := NewColor(color.R, color.G, color.B, 0)
+				vertices, indices = appendTrapezoid(vertices, indices, left.top+dl, right.top-dr, top, left.bottom+dl, right.bottom-dr, bottom, color)
+				vertices, indices = appendTrapezoidColors(vertices, indices, left.top-dl, left.top+dl, top, left.bottom-dl, left.bottom+dl, bottom, clear, color)
+				vertices, indices = appendTrapezoidColors(vertices, indices, right.top-dr, right.top+dr, top, right.bottom-dr, right.bottom+dr, bottom, color, clear)
+				enter = -1
+			}
+		}
+	}
+	return vertices, indices
+}
+
+// Add a trapezoid with horizontal top and bottom edges
+func appendTrapezoid(vertices []float32, indices []uint32, topLeft, topRight, top, bottomLeft, bottomRight, bottom float32, color Color) ([]float32, []uint32) {
+	return appendTrapezoidColors(vertices, indices, topLeft, topRight, top, bottomLeft, bottomRight, bottom, color, color)
+}
+
+// Add a trapezoid fading from the left color to the right one
+func appendTrapezoidColors(vertices []float32, indices []uint32, topLeft, topRight, top, bottomLeft, bottomRight, bottom float32, left, right Color) ([]float32, []uint32) {
+	base := uint32(len(vertices) / 6)
+	vertices = appendShapeVertex(vertices, topLeft, top, left)
+	vertices = appendShapeVertex(vertices, topRight, top, right)
+	vertices = appendShapeVertex(vertices, bottomRight, bottom, right)
+	vertices = appendShapeVertex(vertices, bottomLeft, bottom, left)
+	return vertices, append(indices, base, base+1, base+2, base+2, base+3, base)
+}
+
+func sq32(v float32) float32 { return v * v }
+
+// Height where two edges cross, if they do between their ends
+func edgeCrossing(a, b fillEdge) (float32, bool) {
+	top, bottom := max(a.y0, b.y0), min(a.y1, b.y1)
+	if bottom <= top {
+		return 0, false
+	}
+	// Compare x on the shared height range, a sign change means a crossing
+	d0 := a.xAt(top) - b.xAt(top)
+	d1 := a.xAt(bottom) - b.xAt(bottom)
+	if d0*d1 >= 0 {
+		return 0, false
+	}
+	return top + (bottom-top)*d0/(d0-d1), true
+}
+
+// CatmullRom returns the point t (0..1) of the way from p1 to p2 on a
+// smooth curve through p0, p1, p2 and p3
+func CatmullRom(p0, p1, p2, p3 Vector2, t float32) Vector2 {
+	t2, t3 := t*t, t*t*t
+	return Vector2{
+		0.5 * (2*p1.X + (p2.X-p0.X)*t + (2*p0.X-5*p1.X+4*p2.X-p3.X)*t2 + (3*p1.X-p0.X-3*p2.X+p3.X)*t3),
+		0.5 * (2*p1.Y + (p2.Y-p0.Y)*t + (2*p0.Y-5*p1.Y+4*p2.Y-p3.Y)*t2 + (3*p1.Y-p0.Y-3*p2.Y+p3.Y)*t3),
+	}
+}
+
+// SplinePoint returns a point on the smooth curve through all points
+// t goes from 0 at the first point to len(points)-1 at the last, so
+// whole numbers land exactly on the points. Handy for camera rails.
+func SplinePoint(points []Vector2, t float32) Vector2 {
+	if len(points) == 0 {
+		return Vector2{}
+	}
+	last := len(points) - 1
+	t = max(0, min(t, float32(last)))
+	i := min(int(t), max(last-1, 0))
+	if last == 0 {
+		return points[0]
+	}
+	return CatmullRom(points[max(i-1, 0)], points[i], points[i+1], points[min(i+2, last)], t-float32(i))
+}
+
+// Draw a smooth curve through all points
+func DrawSpline(points []Vector2, style StrokeStyle, color Color) {
+	if len(points) < 2 {
+		return
+	}
+	path := NewPath()
+	path.MoveTo(points[0].X, points[0].Y)
+	path.CatmullRomTo(points[1:]...)
+	path.Stroke(style, color)
+}
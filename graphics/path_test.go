@@ -0,0 +1,86 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+)
+
+// Total area of the triangles of a ShapeVertex mesh
+func meshArea(vertices []float32, indices []uint32) float32 {
+	const stride = 6
+	point := func(i uint32) Vector2 {
+		return Vector2{vertices[i*stride], vertices[i*stride+1]}
+	}
+	var area float32
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := point(indices[i]), point(indices[i+1]), point(indices[i+2])
+		area += abs32(b.Sub(a).Cross(c.Sub(a))) / 2
+	}
+	return area
+}
+
+// Corners of a regular polygon around (50, 50), the first one at the top
+func regularPolygon(corners int, radius float32) []Vector2 {
+	points := make([]Vector2, corners)
+	for i := range points {
+		a := float64(i)*2*math.Pi/float64(corners) - math.Pi/2
+		points[i] = Vec2(50+radius*float32(math.Cos(a)), 50+radius*float32(math.Sin(a)))
+	}
+	return points
+}
+
+// Star around (50, 50) with points at outer and dents at inner radius
+func regularStar(points int, outer, inner float32) []Vector2 {
+	star := make([]Vector2, 0, points*2)
+	for i := range points {
+		a := float64(i)*2*math.Pi/float64(points) - math.Pi/2
+		b := a + math.Pi/float64(points)
+		star = append(star,
+			Vec2(50+outer*float32(math.Cos(a)), 50+outer*float32(math.Sin(a))),
+			Vec2(50+inner*float32(math.Cos(b)), 50+inner*float32(math.Sin(b))))
+	}
+	return star
+}
+
+func square(x, y, size float32) []Vector2 {
+	return []Vector2{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}
+}
+
+func TestTessellateFillRules(t *testing.T) {
+	defer func(saved bool) { antiAlias = saved }(antiAlias)
+	antiAlias = false
+
+	// A pentagram is drawn through every second corner of a pentagon.
+	// Its outline is a ten pointed star around a pentagon where the
+	// path winds twice, even-odd leaves that pentagon empty.
+	const outer = 40
+	corners := regularPolygon(5, outer)
+	var pentagram []Vector2
+	for i := range corners {
+		pentagram = append(pentagram, corners[i*2%5])
+	}
+	inner := outer * float32(math.Cos(2*math.Pi/5)/math.Cos(math.Pi/5))
+	starArea := polygonArea(regularStar(5, outer, inner))
+	centerArea := polygonArea(regularPolygon(5, inner))
+
+	tests := []struct {
+		name     string
+		polygons [][]Vector2
+		rule     FillRule
+		want     float32
+	}{
+		{"pentagram nonzero", [][]Vector2{pentagram}, FillNonZero, starArea},
+		{"pentagram even-odd", [][]Vector2{pentagram}, FillEvenOdd, starArea - centerArea},
+		{"hole same direction nonzero", [][]Vector2{square(0, 0, 10), square(2, 2, 6)}, FillNonZero, 100},
+		{"hole same direction even-odd", [][]Vector2{square(0, 0, 10), square(2, 2, 6)}, FillEvenOdd, 64},
+		{"hole reversed nonzero", [][]Vector2{square(0, 0, 10), reversed(square(2, 2, 6))}, FillNonZero, 64},
+		{"hole reversed even-odd", [][]Vector2{square(0, 0, 10), reversed(square(2, 2, 6))}, FillEvenOdd, 64},
+		{"concave", [][]Vector2{{{0, 0}, {10, 0}, {10, 4}, {4, 4}, {4, 10}, {0, 10}}}, FillNonZero, 64},
+	}
+	for _, tt := range tests {
+		vertices, indices := tessellateFill(tt.polygons, tt.rule, WHITE)
+		if got := meshArea(vertices, indices); abs32(got-tt.want) > tt.want*1e-4 {
+			t.Errorf("%s: filled %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
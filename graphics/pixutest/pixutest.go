@@ -0,0 +1,185 @@
+// Package pixutest renders draw calls off-screen and compares them with
+// golden PNG files, for testing code built on the graphics package.
+//
+// A snapshot test looks like:
+//
+//	func TestRectangle(t *testing.T) {
+//		pixutest.Golden(t, "rectangle", 64, 64, func() {
+//			graphics.DrawRectangle(16, 16, 32, 32, graphics.RED)
+//		}, pixutest.Options{Tolerance: 2})
+//	}
+//
+// Goldens live in testdata/<name>.png. Run the tests with -pixutest.update
+// to write them from the current output:
+//
+//	go test ./... -pixutest.update
+//
+// The software backend needs no display. Add -tags pixu_headless to build
+// without glfw and OpenGL, so CGO_ENABLED=0 works too.
+package pixutest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QOthman/Pixu/graphics"
+)
+
+// Namespaced so it doesn't clash with a test's own -update flag
+var update = flag.Bool("pixutest.update", false, "regenerate pixutest golden images")
+
+// Options control how a frame is compared with its golden.
+type Options struct {
+	Tolerance uint8  // max difference allowed per color channel
+	Dir       string // golden directory, "testdata" by default
+}
+
+// Render draws a frame with the software backend and returns it.
+// The frame starts cleared to transparent black with every drawing
+// setting at its default, see graphics.ResetState. Images loaded by an
+// earlier Render stay valid.
+func Render(width, height int, drawFrame func()) (*image.RGBA, error) {
+	if err := graphics.InitHeadless(width, height); err != nil {
+		return nil, err
+	}
+	graphics.ResetState()
+	drawFrame()
+	graphics.Present()
+	return graphics.Screenshot(), nil
+}
+
+// Golden renders a frame and compares it with testdata/<name>.png.
+func Golden(t testing.TB, name string, width, height int, drawFrame func(), opts Options) {
+	t.Helper()
+	got, err := Render(width, height, drawFrame)
+	if err != nil {
+		t.Fatalf("pixutest: render %s: %v", name, err)
+	}
+	Compare(t, name, got, opts)
+}
+
+// Compare checks an image against testdata/<name>.png. On mismatch the test
+// fails and a diff image is written next to the golden as <name>.diff.png.
+func Compare(t testing.TB, name string, got image.Image, opts Options) {
+	t.Helper()
+	dir := opts.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	path := filepath.Join(dir, name+".png")
+	diffPath := filepath.Join(dir, name+".diff.png")
+
+	if *update {
+		if err := writePNG(path, got); err != nil {
+			t.Fatalf("pixutest: update %s: %v", path, err)
+		}
+		os.Remove(diffPath)
+		return
+	}
+
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("pixutest: %v (run with -pixutest.update to create it)", err)
+	}
+
+	diff, count := Diff(want, got, opts.Tolerance)
+	if count == 0 {
+		os.Remove(diffPath)
+		return
+	}
+	if err := writePNG(diffPath, diff); err != nil {
+		t.Errorf("pixutest: write diff: %v", err)
+	}
+	t.Errorf("pixutest: %s: %d pixels differ by more than %d, see %s", name, count, opts.Tolerance, diffPath)
+}
+
+// Diff compares two images channel by channel. It returns an image with the
+// differing pixels in red over a faded copy of got, and how many pixels differ.
+// Images of different sizes differ in every pixel.
+func Diff(want, got image.Image, tolerance uint8) (*image.RGBA, int) {
+	a, b := toRGBA(want), toRGBA(got)
+	bounds := b.Rect
+	diff := image.NewRGBA(bounds)
+
+	if a.Rect.Size() != b.Rect.Size() {
+		draw.Draw(diff, bounds, &image.Uniform{color.RGBA{255, 0, 0, 255}}, image.Point{}, draw.Src)
+		return diff, max(bounds.Dx()*bounds.Dy(), a.Rect.Dx()*a.Rect.Dy())
+	}
+
+	count := 0
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			i := a.PixOffset(a.Rect.Min.X+x, a.Rect.Min.Y+y)
+			j := b.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+			pa, pb := a.Pix[i:i+4], b.Pix[j:j+4]
+
+			bad := false
+			for c := 0; c < 4; c++ {
+				if absDiff(pa[c], pb[c]) > tolerance {
+					bad = true
+					break
+				}
+			}
+			if bad {
+				count++
+				copy(diff.Pix[j:j+4], []byte{255, 0, 0, 255})
+				continue
+			}
+			gray := uint8((int(pb[0]) + int(pb[1]) + int(pb[2])) / 3 / 4)
+			copy(diff.Pix[j:j+4], []byte{gray, gray, gray, 255})
+		}
+	}
+	return diff, count
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Rect, img, img.Bounds().Min, draw.Src)
+	return rgba
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
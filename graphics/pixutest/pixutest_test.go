@@ -0,0 +1,179 @@
+package pixutest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QOthman/Pixu/graphics"
+)
+
+// Records failures instead of failing the real test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.TB.Fatalf("unexpected fatal: "+format, args...)
+}
+
+func solid(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestDiffTolerance(t *testing.T) {
+	want := solid(2, 1, color.RGBA{100, 100, 100, 255})
+	got := solid(2, 1, color.RGBA{100, 100, 100, 255})
+	got.SetRGBA(1, 0, color.RGBA{103, 100, 100, 255})
+
+	if _, count := Diff(want, got, 3); count != 0 {
+		t.Errorf("difference of 3 with tolerance 3: %d pixels differ, want 0", count)
+	}
+	diff, count := Diff(want, got, 2)
+	if count != 1 {
+		t.Fatalf("difference of 3 with tolerance 2: %d pixels differ, want 1", count)
+	}
+	if c := diff.RGBAAt(1, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("differing pixel is %v in the diff, want red", c)
+	}
+	if c := diff.RGBAAt(0, 0); c.R != c.G || c.A != 255 {
+		t.Errorf("matching pixel is %v in the diff, want opaque gray", c)
+	}
+}
+
+func TestDiffSizeMismatch(t *testing.T) {
+	want := solid(2, 2, color.RGBA{A: 255})
+	got := solid(3, 1, color.RGBA{A: 255})
+	diff, count := Diff(want, got, 255)
+	if count != 4 {
+		t.Errorf("2x2 against 3x1: %d pixels differ, want 4", count)
+	}
+	if diff.Rect != got.Rect {
+		t.Errorf("diff bounds %v, want the bounds of got %v", diff.Rect, got.Rect)
+	}
+}
+
+func TestCompareWritesDiff(t *testing.T) {
+	defer func(saved bool) { *update = saved }(*update)
+	*update = false
+	dir := t.TempDir()
+	if err := writePNG(filepath.Join(dir, "square.png"), solid(4, 4, color.RGBA{0, 0, 255, 255})); err != nil {
+		t.Fatal(err)
+	}
+	diffPath := filepath.Join(dir, "square.diff.png")
+
+	r := &recorder{TB: t}
+	Compare(r, "square", solid(4, 4, color.RGBA{255, 255, 255, 255}), Options{Dir: dir})
+	if len(r.errors) != 1 {
+		t.Fatalf("mismatch reported %d errors, want 1", len(r.errors))
+	}
+	diff, err := readPNG(diffPath)
+	if err != nil {
+		t.Fatalf("diff image not written: %v", err)
+	}
+	if diff.Bounds().Size() != image.Pt(4, 4) {
+		t.Errorf("diff image is %v, want 4x4", diff.Bounds().Size())
+	}
+
+	// A match removes the stale diff
+	r = &recorder{TB: t}
+	Compare(r, "square", solid(4, 4, color.RGBA{0, 0, 255, 255}), Options{Dir: dir})
+	if len(r.errors) != 0 {
+		t.Errorf("match reported errors: %v", r.errors)
+	}
+	if _, err := os.Stat(diffPath); !os.IsNotExist(err) {
+		t.Errorf("diff image still there after a match")
+	}
+}
+
+func TestCompareUpdate(t *testing.T) {
+	defer func(saved bool) { *update = saved }(*update)
+	dir := t.TempDir()
+	img := solid(3, 2, color.RGBA{10, 20, 30, 255})
+
+	*update = true
+	Compare(t, "new", img, Options{Dir: dir})
+	golden, err := readPNG(filepath.Join(dir, "new.png"))
+	if err != nil {
+		t.Fatalf("golden not written: %v", err)
+	}
+	if _, count := Diff(golden, img, 0); count != 0 {
+		t.Errorf("written golden differs in %d pixels", count)
+	}
+
+	*update = false
+	r := &recorder{TB: t}
+	Compare(r, "new", img, Options{Dir: dir})
+	if len(r.errors) != 0 {
+		t.Errorf("compare against the updated golden: %v", r.errors)
+	}
+}
+
+func TestRenderResetsState(t *testing.T) {
+	first, err := Render(16, 16, func() {
+		graphics.DrawRectangle(4, 4, 8, 8, graphics.RED)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Settings left behind must not leak into the next frame
+	if _, err := Render(16, 16, func() {
+		graphics.BeginMode2D(graphics.Camera2D{OffsetX: 5, Zoom: 2})
+		graphics.PushTransform()
+		graphics.SetBlendMode(graphics.BlendAdditive)
+		graphics.BeginScissor(0, 0, 1, 1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	second, err := Render(16, 16, func() {
+		graphics.DrawRectangle(4, 4, 8, 8, graphics.RED)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, count := Diff(first, second, 0); count != 0 {
+		t.Errorf("%d pixels changed after a frame that left settings behind", count)
+	}
+}
+
+func TestGoldenTextCentered(t *testing.T) {
+	Golden(t, "text_centered", 96, 32, func() {
+		graphics.ClearBackground(graphics.BLACK)
+		graphics.DrawTextCentered("Pixu", 48, 16, 1, graphics.WHITE)
+	}, Options{Tolerance: 2})
+}
+
+func TestGoldenRotatedImage(t *testing.T) {
+	Golden(t, "image_rotated", 48, 48, func() {
+		// Quarters of different colors show which way it turned
+		pattern := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				pattern.SetRGBA(x, y, color.RGBA{uint8(x / 4 * 255), uint8(y / 4 * 255), 128, 255})
+			}
+		}
+		img := graphics.NewImageFromImage(pattern)
+		defer img.Delete()
+
+		graphics.ClearBackground(graphics.BLACK)
+		graphics.DrawImageEx(img, graphics.DrawOptions{
+			X: 12, Y: 12, Width: 24, Height: 24,
+			Rotation: 30,
+			Tint:     graphics.WHITE,
+		})
+	}, Options{Tolerance: 2})
+}
@@ -0,0 +1,371 @@
+package graphics
+
+// Built-in post effects. Their fields can be changed between frames,
+// the New* constructors fill them with values that look right.
+// Like all custom shaders they only run on the OpenGL backend,
+// the software backend passes the frame through unchanged.
+
+const blurFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform vec2 uStep; // one texel along the blur direction, scaled by the radius
+
+const float weights[5] = float[](0.2270270, 0.1945946, 0.1216216, 0.0540540, 0.0162162);
+
+void main() {
+    vec4 sum = texture(ourTexture, TexCoord) * weights[0];
+    for (int i = 1; i < 5; i++) {
+        sum += texture(ourTexture, TexCoord + uStep * float(i)) * weights[i];
+        sum += texture(ourTexture, TexCoord - uStep * float(i)) * weights[i];
+    }
+    FragColor = sum;
+}
+`
+
+const brightFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform float uThreshold;
+
+void main() {
+    vec4 color = texture(ourTexture, TexCoord);
+    float luma = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+    FragColor = vec4(color.rgb * smoothstep(uThreshold, uThreshold + 0.1, luma), color.a);
+}
+`
+
+const bloomFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform sampler2D uBloom;
+uniform float uIntensity;
+
+void main() {
+    vec4 color = texture(ourTexture, TexCoord);
+    FragColor = vec4(color.rgb + texture(uBloom, TexCoord).rgb * uIntensity, color.a);
+}
+`
+
+const vignetteFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform float uRadius;
+uniform float uSoftness;
+uniform float uIntensity;
+
+void main() {
+    vec4 color = texture(ourTexture, TexCoord);
+    float shade = 1.0 - smoothstep(uRadius - uSoftness, uRadius, length(TexCoord - 0.5));
+    FragColor = vec4(color.rgb * mix(1.0, shade, uIntensity), color.a);
+}
+`
+
+const crtFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform float uLines;
+uniform float uIntensity;
+uniform float uCurvature;
+
+void main() {
+    // Bulge the picture like a tube screen
+    vec2 uv = TexCoord * 2.0 - 1.0;
+    uv *= 1.0 + uCurvature * dot(uv.yx, uv.yx);
+    uv = uv * 0.5 + 0.5;
+    if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+        FragColor = vec4(0.0, 0.0, 0.0, 1.0);
+        return;
+    }
+
+    vec4 color = texture(ourTexture, uv);
+    float line = sin(uv.y * uLines * 3.14159265) * 0.5 + 0.5;
+    FragColor = vec4(color.rgb * (1.0 - uIntensity * (1.0 - line)), color.a);
+}
+`
+
+const grayscaleFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform float uAmount;
+
+void main() {
+    vec4 color = texture(ourTexture, TexCoord);
+    float luma = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+    FragColor = vec4(mix(color.rgb, vec3(luma), uAmount), color.a);
+}
+`
+
+const lutFragmentSource = `
+#version 330 core
+in vec2 TexCoord;
+out vec4 FragColor;
+
+uniform sampler2D ourTexture;
+uniform sampler2D uLut;
+uniform float uSize;
+uniform float uStrength;
+
+// The LUT is uSize squares of uSize x uSize laid out left to right,
+// blue picks the square, red goes right and green goes down
+vec3 lookup(vec3 color, float slice) {
+    vec2 uv = vec2((slice * uSize + color.r * (uSize - 1.0) + 0.5) / (uSize * uSize),
+                   1.0 - (color.g * (uSize - 1.0) + 0.5) / uSize);
+    return texture(uLut, uv).rgb;
+}
+
+void main() {
+    vec4 color = texture(ourTexture, TexCoord);
+    vec3 c = clamp(color.rgb, 0.0, 1.0);
+    float blue = c.b * (uSize - 1.0);
+    float slice = floor(blue);
+    vec3 graded = mix(lookup(c, slice), lookup(c, min(slice + 1.0, uSize - 1.0)), blue - slice);
+    FragColor = vec4(mix(color.rgb, graded, uStrength), color.a);
+}
+`
+
+// GaussianBlur blurs the frame in two passes, horizontal then vertical
+type GaussianBlur struct {
+	Radius float32 // spread in pixels per sample, 1 is a light blur
+
+	shader *Shader
+	temp   *RenderTarget
+}
+
+// NewGaussianBlur creates a blur of the given spread
+func NewGaussianBlur(radius float32) *GaussianBlur {
+	return &GaussianBlur{Radius: radius}
+}
+
+// Prepare compiles the blur shader
+func (e *GaussianBlur) Prepare() error {
+	_, err := effectShader(&e.shader, blurFragmentSource)
+	return err
+}
+
+func (e *GaussianBlur) Apply(src *Image, dst *RenderTarget) error {
+	shader, err := effectShader(&e.shader, blurFragmentSource)
+	if err != nil {
+		return err
+	}
+	temp, err := ensureTarget(&e.temp, int(src.Width), int(src.Height))
+	if err != nil {
+		return err
+	}
+	blurPasses(shader, src, temp, dst, e.Radius)
+	return nil
+}
+
+// Blur src into dst, using temp for the horizontal pass
+func blurPasses(shader *Shader, src *Image, temp, dst *RenderTarget, radius float32) {
+	shader.SetUniformVec2("uStep", radius/float32(src.Width), 0)
+	DrawPass(src, temp, shader)
+	shader.SetUniformVec2("uStep", 0, radius/float32(src.Height))
+	DrawPass(temp.Image, dst, shader)
+}
+
+// Bloom makes bright areas glow: they are extracted, blurred and added back
+type Bloom struct {
+	Threshold float32 // brightness (0..1) above which pixels glow
+	Intensity float32 // how strong the glow is added back
+	Radius    float32 // blur spread in pixels per sample
+
+	bright, blur, combine *Shader
+	glow, temp            *RenderTarget
+}
+
+// NewBloom creates a bloom that makes the brightest parts glow softly
+func NewBloom() *Bloom {
+	return &Bloom{Threshold: 0.7, Intensity: 1, Radius: 2}
+}
+
+// Prepare compiles the bloom shaders
+func (e *Bloom) Prepare() error {
+	for _, s := range []struct {
+		shader **Shader
+		source string
+	}{
+		{&e.bright, brightFragmentSource},
+		{&e.blur, blurFragmentSource},
+		{&e.combine, bloomFragmentSource},
+	} {
+		if _, err := effectShader(s.shader, s.source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Bloom) Apply(src *Image, dst *RenderTarget) error {
+	if err := e.Prepare(); err != nil {
+		return err
+	}
+	glow, err := ensureTarget(&e.glow, int(src.Width), int(src.Height))
+	if err != nil {
+		return err
+	}
+	temp, err := ensureTarget(&e.temp, int(src.Width), int(src.Height))
+	if err != nil {
+		return err
+	}
+
+	e.bright.SetUniformFloat("uThreshold", e.Threshold)
+	DrawPass(src, glow, e.bright)
+	blurPasses(e.blur, glow.Image, temp, glow, e.Radius)
+
+	e.combine.SetUniformFloat("uIntensity", e.Intensity)
+	e.combine.SetUniformTexture("uBloom", glow.Image)
+	DrawPass(src, dst, e.combine)
+	return nil
+}
+
+// Vignette darkens the frame towards its corners
+type Vignette struct {
+	Radius    float32 // distance from the center where darkening ends, 0.5 reaches the edges
+	Softness  float32 // width of the fade
+	Intensity float32 // 0 no effect, 1 fully dark outside the radius
+
+	shader *Shader
+}
+
+// NewVignette creates a vignette that gently darkens the corners
+func NewVignette() *Vignette {
+	return &Vignette{Radius: 0.75, Softness: 0.45, Intensity: 0.8}
+}
+
+// Prepare compiles the vignette shader
+func (e *Vignette) Prepare() error {
+	_, err := effectShader(&e.shader, vignetteFragmentSource)
+	return err
+}
+
+func (e *Vignette) Apply(src *Image, dst *RenderTarget) error {
+	shader, err := effectShader(&e.shader, vignetteFragmentSource)
+	if err != nil {
+		return err
+	}
+	shader.SetUniformFloat("uRadius", e.Radius)
+	shader.SetUniformFloat("uSoftness", e.Softness)
+	shader.SetUniformFloat("uIntensity", e.Intensity)
+	DrawPass(src, dst, shader)
+	return nil
+}
+
+// CRT imitates a tube screen with scanlines and a curved picture
+type CRT struct {
+	Lines     float32 // number of scanlines, 0 uses half the frame height
+	Intensity float32 // how dark the gaps between lines are, 0..1
+	Curvature float32 // screen bulge, 0 is flat, 0.1 is noticeable
+
+	shader *Shader
+}
+
+// NewCRT creates a CRT look with visible scanlines and a slight bulge
+func NewCRT() *CRT {
+	return &CRT{Intensity: 0.3, Curvature: 0.05}
+}
+
+// Prepare compiles the CRT shader
+func (e *CRT) Prepare() error {
+	_, err := effectShader(&e.shader, crtFragmentSource)
+	return err
+}
+
+func (e *CRT) Apply(src *Image, dst *RenderTarget) error {
+	shader, err := effectShader(&e.shader, crtFragmentSource)
+	if err != nil {
+		return err
+	}
+	lines := e.Lines
+	if lines == 0 {
+		lines = float32(src.Height) / 2
+	}
+	shader.SetUniformFloat("uLines", lines)
+	shader.SetUniformFloat("uIntensity", e.Intensity)
+	shader.SetUniformFloat("uCurvature", e.Curvature)
+	DrawPass(src, dst, shader)
+	return nil
+}
+
+// Grayscale removes color from the frame
+type Grayscale struct {
+	Amount float32 // 0 keeps the colors, 1 is fully gray
+
+	shader *Shader
+}
+
+// NewGrayscale creates a fully gray effect
+func NewGrayscale() *Grayscale {
+	return &Grayscale{Amount: 1}
+}
+
+// Prepare compiles the grayscale shader
+func (e *Grayscale) Prepare() error {
+	_, err := effectShader(&e.shader, grayscaleFragmentSource)
+	return err
+}
+
+func (e *Grayscale) Apply(src *Image, dst *RenderTarget) error {
+	shader, err := effectShader(&e.shader, grayscaleFragmentSource)
+	if err != nil {
+		return err
+	}
+	shader.SetUniformFloat("uAmount", e.Amount)
+	DrawPass(src, dst, shader)
+	return nil
+}
+
+// ColorGrade remaps colors through a lookup table image.
+// The LUT is Size squares of Size x Size pixels side by side (a 256x16
+// strip for Size 16): blue picks the square, red grows to the right
+// and green grows downwards. An identity LUT leaves the frame unchanged.
+type ColorGrade struct {
+	LUT      *Image
+	Strength float32 // 0 keeps the frame, 1 is fully graded
+
+	shader *Shader
+}
+
+// NewColorGrade creates a full strength grade through a LUT
+func NewColorGrade(lut *Image) *ColorGrade {
+	return &ColorGrade{LUT: lut, Strength: 1}
+}
+
+// Prepare compiles the grading shader
+func (e *ColorGrade) Prepare() error {
+	_, err := effectShader(&e.shader, lutFragmentSource)
+	return err
+}
+
+func (e *ColorGrade) Apply(src *Image, dst *RenderTarget) error {
+	shader, err := effectShader(&e.shader, lutFragmentSource)
+	if err != nil {
+		return err
+	}
+	if e.LUT == nil {
+		DrawPass(src, dst, nil)
+		return nil
+	}
+	shader.SetUniformTexture("uLut", e.LUT)
+	shader.SetUniformFloat("uSize", float32(e.LUT.Height))
+	shader.SetUniformFloat("uStrength", e.Strength)
+	DrawPass(src, dst, shader)
+	return nil
+}
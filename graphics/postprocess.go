@@ -0,0 +1,234 @@
+package graphics
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// PostEffect is one step of a PostProcess chain.
+// Apply reads src and draws the result into dst, usually with DrawPass.
+// An effect can also have a Prepare() error method to compile its shaders
+// up front, it is called by Add and SetPostProcess.
+type PostEffect interface {
+	Apply(src *Image, dst *RenderTarget) error
+}
+
+// Effects that set things up before their first frame
+type postEffectPreparer interface {
+	Prepare() error
+}
+
+// PostProcess renders the frame off-screen and runs it through
+// its effects, in order, before Present shows it.
+type PostProcess struct {
+	Effects []PostEffect
+
+	scene, ping, pong *RenderTarget
+	capturing         bool
+	err               error // first failure while running, see Err
+}
+
+// Chain used by Present, set with SetPostProcess
+var activePost *PostProcess
+
+// NewPostProcess creates a chain with the given effects
+// They are prepared when the chain is passed to SetPostProcess.
+func NewPostProcess(effects ...PostEffect) *PostProcess {
+	return &PostProcess{Effects: effects}
+}
+
+// Add prepares an effect and appends it to the end of the chain
+// The effect is not added if preparing it fails.
+func (p *PostProcess) Add(effect PostEffect) error {
+	if err := prepareEffect(effect); err != nil {
+		return err
+	}
+	p.Effects = append(p.Effects, effect)
+	return nil
+}
+
+// Err returns the first error met while running the chain, like a target
+// that could not be resized. The frame is shown unprocessed from that step.
+func (p *PostProcess) Err() error {
+	return p.err
+}
+
+// SetPostProcess sends every frame through the chain, nil turns it off
+// Everything drawn until Present goes to the chain's scene target.
+// The effects are prepared first, if one fails the chain is not enabled.
+func SetPostProcess(p *PostProcess) error {
+	if p != nil {
+		for _, effect := range p.Effects {
+			if err := prepareEffect(effect); err != nil {
+				return err
+			}
+		}
+		if _, err := ensureTarget(&p.scene, windowWidth, windowHeight); err != nil {
+			return err
+		}
+	}
+	if activePost != nil && activePost.capturing {
+		activePost.endCapture()
+	}
+	activePost = p
+	if p != nil {
+		p.begin()
+	}
+	return nil
+}
+
+func prepareEffect(effect PostEffect) error {
+	if e, ok := effect.(postEffectPreparer); ok {
+		return e.Prepare()
+	}
+	return nil
+}
+
+// Delete frees the chain's render targets
+func (p *PostProcess) Delete() {
+	if activePost == p {
+		SetPostProcess(nil)
+	}
+	for _, rt := range []*RenderTarget{p.scene, p.ping, p.pong} {
+		if rt != nil {
+			rt.Delete()
+		}
+	}
+	p.scene, p.ping, p.pong = nil, nil, nil
+}
+
+// Start capturing a frame, resizing the targets with the window
+func (p *PostProcess) begin() {
+	if _, err := ensureTarget(&p.scene, windowWidth, windowHeight); err != nil {
+		p.fail(err)
+		return
+	}
+	BeginRenderTarget(p.scene)
+	p.capturing = true
+}
+
+// Stop capturing, closing targets the frame left open on top of the scene
+// If the scene is not on the stack at all, nothing is popped.
+func (p *PostProcess) endCapture() bool {
+	p.capturing = false
+	i := len(targetStack) - 1
+	for i >= 0 && targetStack[i] != p.scene {
+		i--
+	}
+	if i < 0 {
+		p.fail(errors.New("post process: the scene target was ended by someone else"))
+		return false
+	}
+	if i != len(targetStack)-1 {
+		p.fail(errors.New("post process: a render target was left open at Present"))
+	}
+	for len(targetStack) > i {
+		EndRenderTarget()
+	}
+	return true
+}
+
+// Stop capturing and draw the processed frame to the window
+func (p *PostProcess) finish() {
+	if !p.capturing || !p.endCapture() {
+		return
+	}
+
+	src := p.scene.Image
+	for _, effect := range p.Effects {
+		dst, err := ensureTarget(&p.ping, windowWidth, windowHeight)
+		if err == nil && dst.Image == src {
+			dst, err = ensureTarget(&p.pong, windowWidth, windowHeight)
+		}
+		if err == nil {
+			err = effect.Apply(src, dst)
+		}
+		if err != nil {
+			p.fail(err)
+			break
+		}
+		src = dst.Image
+	}
+	DrawPass(src, nil, nil)
+}
+
+// Remember the first error
+func (p *PostProcess) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// DrawPass draws src stretched over dst (the window if nil) with a shader
+// (a plain copy if nil). Camera and transforms are ignored and nothing
+// is blended, the pass replaces what dst held.
+func DrawPass(src *Image, dst *RenderTarget, shader *Shader) {
+	if dst != nil {
+		BeginRenderTarget(dst)
+	}
+	savedView, savedModel, savedShader := viewMatrix, modelMatrix, currentShader
+	viewMatrix, modelMatrix, currentShader = MatrixIdentity(), MatrixIdentity(), shader
+
+	w, h := float32(surfaceWidth), float32(surfaceHeight)
+	vertices := []float32{
+		0, 0, 0, 1, 1, 1, 1, 1, // top-left
+		w, 0, 1, 1, 1, 1, 1, 1, // top-right
+		w, h, 1, 0, 1, 1, 1, 1, // bottom-right
+		0, h, 0, 0, 1, 1, 1, 1, // bottom-left
+	}
+	state := currentState(TextureVertex, Triangles, src.TextureID)
+	state.blend = BlendReplace
+	state.scissor, state.clipped, state.stencil = image.Rectangle{}, false, StencilOff
+	batchAppend(state, vertices, []uint32{0, 1, 2, 2, 3, 0})
+	flushBatch()
+
+	viewMatrix, modelMatrix, currentShader = savedView, savedModel, savedShader
+	if dst != nil {
+		EndRenderTarget()
+	}
+}
+
+// ShaderPass is a user effect: one fullscreen draw with a shader.
+// Uniforms, if set, is called before drawing to update the shader.
+type ShaderPass struct {
+	Shader   *Shader
+	Uniforms func(shader *Shader, src *Image)
+}
+
+func (p *ShaderPass) Apply(src *Image, dst *RenderTarget) error {
+	if p.Uniforms != nil && p.Shader != nil {
+		p.Uniforms(p.Shader, src)
+	}
+	DrawPass(src, dst, p.Shader)
+	return nil
+}
+
+// Create or resize a render target held by an effect
+func ensureTarget(rt **RenderTarget, width, height int) (*RenderTarget, error) {
+	if *rt != nil && int((*rt).Image.Width) == width && int((*rt).Image.Height) == height {
+		return *rt, nil
+	}
+	if *rt != nil {
+		(*rt).Delete()
+		*rt = nil
+	}
+	target, err := NewRenderTarget(width, height)
+	if err != nil {
+		return nil, fmt.Errorf("post process target: %w", err)
+	}
+	*rt = target
+	return target, nil
+}
+
+// Compile a built-in effect shader the first time it is needed
+func effectShader(shader **Shader, fragmentSource string) (*Shader, error) {
+	if *shader == nil {
+		s, err := LoadShaderFromSource("", fragmentSource)
+		if err != nil {
+			return nil, fmt.Errorf("post effect shader: %w", err)
+		}
+		*shader = s
+	}
+	return *shader, nil
+}
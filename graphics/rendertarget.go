@@ -0,0 +1,74 @@
+package graphics
+
+import "slices"
+
+// RenderTarget is an off-screen surface that Draw* calls can be redirected to.
+// Its content is available as an Image for DrawImage and friends.
+type RenderTarget struct {
+	Image *Image // Color attachment, draw it like any other image
+	id    uint32
+}
+
+// Targets started with BeginRenderTarget, innermost last
+var targetStack []*RenderTarget
+
+// NewRenderTarget creates an off-screen surface of the given size
+func NewRenderTarget(width, height int) (*RenderTarget, error) {
+	id, textureID, err := backend.NewRenderTarget(width, height)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderTarget{
+		Image: &Image{
+			TextureID: textureID,
+			Width:     int32(width),
+			Height:    int32(height),
+		},
+		id: id,
+	}, nil
+}
+
+// BeginRenderTarget sends all following draws to the target until EndRenderTarget
+// Targets can be nested, EndRenderTarget goes back to the previous one.
+func BeginRenderTarget(rt *RenderTarget) {
+	flushBatch()
+	targetStack = append(targetStack, rt)
+	bindSurface()
+}
+
+// EndRenderTarget goes back to drawing on the previous target or the window
+func EndRenderTarget() {
+	if len(targetStack) == 0 {
+		return
+	}
+	flushBatch()
+	targetStack = targetStack[:len(targetStack)-1]
+	bindSurface()
+}
+
+// Delete frees the target and its image
+// A target still in use is ended first, draws queued into it land there.
+func (rt *RenderTarget) Delete() {
+	if slices.Contains(targetStack, rt) {
+		flushBatch()
+		targetStack = slices.DeleteFunc(targetStack, func(t *RenderTarget) bool { return t == rt })
+		bindSurface()
+	} else if batch.state.texture == rt.Image.TextureID {
+		flushBatch()
+	}
+	backend.DeleteRenderTarget(rt.id)
+	rt.Image.TextureID = 0
+	rt.id = 0
+}
+
+// Bind the innermost target, or the window when there is none
+func bindSurface() {
+	if len(targetStack) == 0 {
+		surfaceWidth, surfaceHeight = windowWidth, windowHeight
+		backend.BindRenderTarget(0, windowWidth, windowHeight)
+		return
+	}
+	rt := targetStack[len(targetStack)-1]
+	surfaceWidth, surfaceHeight = int(rt.Image.Width), int(rt.Image.Height)
+	backend.BindRenderTarget(rt.id, surfaceWidth, surfaceHeight)
+}
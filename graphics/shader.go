@@ -0,0 +1,142 @@
+package graphics
+
+import (
+	"os"
+)
+
+// Shader is a user shader program drawn with between BeginShader and EndShader.
+//
+// Custom shaders see the same inputs as the built-in image shader,
+// shapes are drawn with a white texture so one shader handles both:
+//
+//	in vec2 TexCoord;              // texture coordinate
+//	in vec4 Color;                 // tint or shape color
+//	uniform sampler2D ourTexture;  // image being drawn (white for shapes)
+//	out vec4 FragColor;
+//
+// The vertex stage gets aPos (location 0), aTexCoord (1), aColor (2)
+// and uniform mat3 uTransform, which maps aPos to OpenGL coordinates.
+//
+// The software backend accepts shaders but draws with the built-in ones.
+type Shader struct {
+	id uint32
+}
+
+// Shader used by the Draw* functions, nil for the built-in ones
+var currentShader *Shader
+
+// 1x1 white texture used to draw shapes through custom shaders
+var whiteTexture uint32
+
+// LoadShaderFromSource compiles a shader from GLSL source strings
+// An empty source uses the built-in stage. Compile and link errors are returned.
+func LoadShaderFromSource(vertexSource, fragmentSource string) (*Shader, error) {
+	if vertexSource == "" {
+		vertexSource = textureVertexShaderSource
+	}
+	if fragmentSource == "" {
+		fragmentSource = textureFragmentShaderSource
+	}
+	id, err := backend.NewShader(vertexSource, fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	return &Shader{id: id}, nil
+}
+
+// LoadShader compiles a shader from GLSL files
+// An empty path uses the built-in stage.
+func LoadShader(vertexPath, fragmentPath string) (*Shader, error) {
+	var vertexSource, fragmentSource string
+	if vertexPath != "" {
+		data, err := os.ReadFile(vertexPath)
+		if err != nil {
+			return nil, err
+		}
+		vertexSource = string(data)
+	}
+	if fragmentPath != "" {
+		data, err := os.ReadFile(fragmentPath)
+		if err != nil {
+			return nil, err
+		}
+		fragmentSource = string(data)
+	}
+	return LoadShaderFromSource(vertexSource, fragmentSource)
+}
+
+// BeginShader draws everything after it with the shader
+func BeginShader(shader *Shader) {
+	currentShader = shader
+}
+
+// EndShader goes back to the built-in shaders
+func EndShader() {
+	currentShader = nil
+}
+
+// SetUniformFloat sets a float uniform
+func (s *Shader) SetUniformFloat(name string, value float32) {
+	s.setUniform(name, []float32{value})
+}
+
+// SetUniformVec2 sets a vec2 uniform
+func (s *Shader) SetUniformVec2(name string, x, y float32) {
+	s.setUniform(name, []float32{x, y})
+}
+
+// SetUniformVec4 sets a vec4 uniform
+func (s *Shader) SetUniformVec4(name string, x, y, z, w float32) {
+	s.setUniform(name, []float32{x, y, z, w})
+}
+
+// SetUniformColor sets a vec4 uniform from a color
+func (s *Shader) SetUniformColor(name string, color Color) {
+	s.setUniform(name, []float32{color.R, color.G, color.B, color.A})
+}
+
+// SetUniformMat3 sets a mat3 uniform
+func (s *Shader) SetUniformMat3(name string, m Matrix) {
+	s.setUniform(name, m[:])
+}
+
+// SetUniformTexture binds an image to a sampler2D uniform, nil unbinds it
+// ourTexture is reserved for the image being drawn.
+func (s *Shader) SetUniformTexture(name string, img *Image) {
+	var texture uint32
+	if img != nil {
+		texture = img.TextureID
+	}
+	s.flushIfUsed()
+	backend.SetUniformTexture(s.id, name, texture)
+}
+
+// Delete frees the shader program
+func (s *Shader) Delete() {
+	s.flushIfUsed()
+	if currentShader == s {
+		currentShader = nil
+	}
+	backend.DeleteShader(s.id)
+	s.id = 0
+}
+
+func (s *Shader) setUniform(name string, values []float32) {
+	s.flushIfUsed()
+	backend.SetUniform(s.id, name, values)
+}
+
+// Pending draws must see the old uniform values
+func (s *Shader) flushIfUsed() {
+	if batch.state.shader == s.id {
+		flushBatch()
+	}
+}
+
+// Id of the active custom shader, 0 for the built-in ones
+func currentShaderID() uint32 {
+	if currentShader == nil {
+		return 0
+	}
+	return currentShader.id
+}
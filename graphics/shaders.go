@@ -1,64 +1,109 @@
+//go:build !pixu_headless
+
 package graphics
 
 import (
-	"log"
+	"fmt"
+	"strings"
+
 	"github.com/go-gl/gl/v3.3-core/gl"
-	"unsafe"
 )
 
 const vertexShaderSource = `
 #version 330 core
 layout (location = 0) in vec2 aPos;
-layout (location = 1) in vec3 aColor;
+layout (location = 1) in vec4 aColor;
+
+uniform mat3 uTransform;
 
-out vec3 vertexColor;
+out vec4 vertexColor;
 
 void main() {
-    gl_Position = vec4(aPos, 0.0, 1.0);
+    vec3 pos = uTransform * vec3(aPos, 1.0);
+    gl_Position = vec4(pos.xy, 0.0, 1.0);
     vertexColor = aColor;
 }
 ` + "\x00"
 
 const fragmentShaderSource = `
 #version 330 core
-in vec3 vertexColor;
+in vec4 vertexColor;
 out vec4 FragColor;
 
+uniform float uAlphaCutoff; // set while drawing a mask, -1 otherwise
+
 void main() {
-    FragColor = vec4(vertexColor, 1.0);
+    if (vertexColor.a <= uAlphaCutoff) {
+        discard;
+    }
+    FragColor = vertexColor;
 }
 ` + "\x00"
 
-func setupShaders() {
-	vertexShader := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
-	fragmentShader := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
-	
-	shaderProgram = gl.CreateProgram()
-	gl.AttachShader(shaderProgram, vertexShader)
-	gl.AttachShader(shaderProgram, fragmentShader)
-	gl.LinkProgram(shaderProgram)
-	
-	gl.DeleteShader(vertexShader)
-	gl.DeleteShader(fragmentShader)
+func setupShaders(pipe *pipeline) error {
+	program, err := linkProgram(vertexShaderSource, fragmentShaderSource)
+	if err != nil {
+		return err
+	}
+	pipe.program = program
+	pipe.transform = gl.GetUniformLocation(pipe.program, gl.Str("uTransform\x00"))
+	pipe.alphaCutoff = gl.GetUniformLocation(pipe.program, gl.Str("uAlphaCutoff\x00"))
+	return nil
 }
 
-func setupBuffers() {
-	gl.GenVertexArrays(1, &currentVAO)
-	gl.GenBuffers(1, &currentVBO)
+func setupBuffers(pipe *pipeline) {
+	gl.GenVertexArrays(1, &pipe.vao)
+	gl.GenBuffers(1, &pipe.vbo)
+	gl.GenBuffers(1, &pipe.ebo)
 	
-	gl.BindVertexArray(currentVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, currentVBO)
+	gl.BindVertexArray(pipe.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, pipe.vbo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, pipe.ebo)
 	
 	// Position attribute
-	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 5*4, nil)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 6*4, nil)
 	gl.EnableVertexAttribArray(0)
 	
 	// Color attribute
-	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, 5*4, unsafe.Pointer(uintptr(2*4)))
+	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 6*4, gl.PtrOffset(2*4))
 	gl.EnableVertexAttribArray(1)
 }
 
-func compileShader(source string, shaderType uint32) uint32 {
+// Compile both stages and link them into a program
+func linkProgram(vertexSource, fragmentSource string) (uint32, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(vertexShader)
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+	gl.LinkProgram(program)
+
+	var success int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &success)
+	if success == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+		logText := make([]byte, logLength+1)
+		gl.GetProgramInfoLog(program, logLength, nil, &logText[0])
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("shader link error: %s", strings.TrimRight(string(logText), "\x00\n"))
+	}
+	return program, nil
+}
+
+func compileShader(source string, shaderType uint32) (uint32, error) {
+	if !strings.HasSuffix(source, "\x00") {
+		source += "\x00"
+	}
 	shader := gl.CreateShader(shaderType)
 	csources, free := gl.Strs(source)
 	gl.ShaderSource(shader, 1, csources, nil)
@@ -70,9 +115,10 @@ func compileShader(source string, shaderType uint32) uint32 {
 	if success == gl.FALSE {
 		var logLength int32
 		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-		logText := make([]byte, logLength)
+		logText := make([]byte, logLength+1)
 		gl.GetShaderInfoLog(shader, logLength, nil, &logText[0])
-		log.Printf("Shader compilation error: %s", string(logText))
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("shader compilation error: %s", strings.TrimRight(string(logText), "\x00\n"))
 	}
-	return shader
-}
\ No newline at end of file
+	return shader, nil
+}
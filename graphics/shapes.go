@@ -2,90 +2,276 @@ package graphics
 
 import (
 	"math"
-
-	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 // Draw line between two points
 func DrawLine(x1, y1, x2, y2 float32, color Color) {
-	x1GL, y1GL := screenToGL(x1, y1)
-	x2GL, y2GL := screenToGL(x2, y2)
-
-	vertices := []float32{
-		x1GL, y1GL, color.R, color.G, color.B,
-		x2GL, y2GL, color.R, color.G, color.B,
-	}
+	DrawLineColors(x1, y1, x2, y2, color, color)
+}
 
-	drawVertices(vertices, 2, gl.LINES)
+// Draw line fading from color1 at the first point to color2 at the second
+func DrawLineColors(x1, y1, x2, y2 float32, color1, color2 Color) {
+	drawLineQuad(x1, y1, x2, y2, 1, color1, color2)
 }
 
 // Draw triangle between three points
 func DrawTriangle(x1, y1, x2, y2, x3, y3 float32, color Color) {
-	glX1, glY1 := screenToGL(x1, y1)
-	glX2, glY2 := screenToGL(x2, y2)
-	glX3, glY3 := screenToGL(x3, y3)
+	DrawTriangleColors(x1, y1, x2, y2, x3, y3, color, color, color)
+}
 
-	vertices := []float32{
-		glX1, glY1, color.R, color.G, color.B,
-		glX2, glY2, color.R, color.G, color.B,
-		glX3, glY3, color.R, color.G, color.B,
-	}
+// Draw triangle with one color per point, blended across the surface
+func DrawTriangleColors(x1, y1, x2, y2, x3, y3 float32, color1, color2, color3 Color) {
+	vertices := make([]float32, 0, 3*6)
+	vertices = appendShapeVertex(vertices, x1, y1, color1)
+	vertices = appendShapeVertex(vertices, x2, y2, color2)
+	vertices = appendShapeVertex(vertices, x3, y3, color3)
 
-	drawVertices(vertices, 3, gl.TRIANGLES)
+	drawShape(vertices, []uint32{0, 1, 2}, Triangles)
 }
 
-// Draw rectangle 
+// Draw rectangle
 func DrawRectangle(x, y, width, height float32, color Color) {
-	glX, glY := screenToGL(x, y)
-	glW := width / float32(windowWidth) * 2.0
-	glH := height / float32(windowHeight) * 2.0
+	DrawRectangleGradient(x, y, width, height, color, color, color, color)
+}
 
-	vertices := []float32{
-		glX, glY, color.R, color.G, color.B, // top-left
-		glX + glW, glY, color.R, color.G, color.B, // top-right
-		glX + glW, glY - glH, color.R, color.G, color.B, // bottom-right
-		glX, glY - glH, color.R, color.G, color.B, // bottom-left
-	}
+// Draw rectangle with one color per corner
+func DrawRectangleGradient(x, y, width, height float32, topLeft, topRight, bottomRight, bottomLeft Color) {
+	vertices := make([]float32, 0, 4*6)
+	vertices = appendShapeVertex(vertices, x, y, topLeft)                  // top-left
+	vertices = appendShapeVertex(vertices, x+width, y, topRight)           // top-right
+	vertices = appendShapeVertex(vertices, x+width, y+height, bottomRight) // bottom-right
+	vertices = appendShapeVertex(vertices, x, y+height, bottomLeft)        // bottom-left
 
 	indices := []uint32{0, 1, 2, 2, 3, 0}
-	drawIndexed(vertices, indices)
+	drawShape(vertices, indices, Triangles)
+}
+
+// Draw rectangle fading from top to bottom
+func DrawRectangleGradientV(x, y, width, height float32, top, bottom Color) {
+	DrawRectangleGradient(x, y, width, height, top, top, bottom, bottom)
+}
+
+// Draw rectangle fading from left to right
+func DrawRectangleGradientH(x, y, width, height float32, left, right Color) {
+	DrawRectangleGradient(x, y, width, height, left, right, right, left)
 }
 
 // Draw circle by center and radius
-// Circle is drawn using triangle fan with segments
 func DrawCircle(centerX, centerY, radius float32, color Color) {
-	const segments = 32
-	vertices := make([]float32, 0, (segments+2)*5) // center + segments + first point again
+	DrawCircleGradient(centerX, centerY, radius, color, color)
+}
+
+// Draw circle fading from inner at the center to outer at the edge
+func DrawCircleGradient(centerX, centerY, radius float32, inner, outer Color) {
+	points := ellipsePoints(centerX, centerY, radius, radius, 0, 360)
+	drawFan(Vector2{centerX, centerY}, points[:len(points)-1], true, inner, outer)
+}
 
-	// Center point
-	glX, glY := screenToGL(centerX, centerY)
-	vertices = append(vertices, glX, glY, color.R, color.G, color.B)
+// Draw ellipse by center and radii
+func DrawEllipse(centerX, centerY, radiusX, radiusY float32, color Color) {
+	points := ellipsePoints(centerX, centerY, radiusX, radiusY, 0, 360)
+	drawFan(Vector2{centerX, centerY}, points[:len(points)-1], true, color, color)
+}
 
-	// Circle points
-	for i := 0; i <= segments; i++ {
-		angle := float32(i) * 2.0 * math.Pi / segments
-		x := centerX + radius*float32(math.Cos(float64(angle)))
-		y := centerY + radius*float32(math.Sin(float64(angle)))
-		glPx, glPy := screenToGL(x, y)
-		vertices = append(vertices, glPx, glPy, color.R, color.G, color.B)
+// Draw ellipse outline centered on the radii
+func DrawEllipseOutline(centerX, centerY, radiusX, radiusY, thickness float32, color Color) {
+	points := ellipsePoints(centerX, centerY, radiusX, radiusY, 0, 360)
+	DrawPolylineClosed(points[:len(points)-1], StrokeStyle{Thickness: thickness}, color)
+}
+
+// Draw part of a circle outline between two angles
+// Angles are in degrees, 0 points right and positive is counter-clockwise.
+func DrawArc(centerX, centerY, radius, startAngle, endAngle, thickness float32, color Color) {
+	if abs32(endAngle-startAngle) >= 360 {
+		DrawCircleOutline(centerX, centerY, radius, thickness, color)
+		return
+	}
+	points := ellipsePoints(centerX, centerY, radius, radius, startAngle, endAngle)
+	DrawPolyline(points, StrokeStyle{Thickness: thickness}, color)
+}
+
+// Draw the part of a ring between two angles
+// An inner radius of 0 draws a pie slice. Angles are like DrawArc.
+func DrawRing(centerX, centerY, innerRadius, outerRadius, startAngle, endAngle float32, color Color) {
+	full := abs32(endAngle-startAngle) >= 360
+	if full {
+		endAngle = startAngle + 360
+	}
+	outer := ellipsePoints(centerX, centerY, outerRadius, outerRadius, startAngle, endAngle)
+	if innerRadius <= 0 {
+		if full {
+			outer = outer[:len(outer)-1]
+		}
+		drawFan(Vector2{centerX, centerY}, outer, full, color, color)
+		return
 	}
 
-	drawVertices(vertices, int32(len(vertices)/5), gl.TRIANGLE_FAN)
+	// Strip between the arcs, same number of points on both
+	vertices := make([]float32, 0, len(outer)*2*6)
+	indices := make([]uint32, 0, (len(outer)-1)*6)
+	sweep := (endAngle - startAngle) * math.Pi / 180
+	for i, p := range outer {
+		angle := float64(startAngle*math.Pi/180 + sweep*float32(i)/float32(len(outer)-1))
+		vertices = appendShapeVertex(vertices, p.X, p.Y, color)
+		vertices = appendShapeVertex(vertices,
+			centerX+innerRadius*float32(math.Cos(angle)),
+			centerY-innerRadius*float32(math.Sin(angle)), color)
+		if i > 0 {
+			o := uint32(i * 2)
+			indices = append(indices, o-2, o, o+1, o+1, o-1, o-2)
+		}
+	}
+	drawShape(vertices, indices, Triangles)
 }
 
-// Draw rectangle outline
-func DrawRectangleOutline(x, y, width, height float32, color Color) {
-	glX, glY := screenToGL(x, y)
-	glW := width / float32(windowWidth) * 2.0
-	glH := height / float32(windowHeight) * 2.0
+// Draw rectangle with rounded corners
+func DrawRoundedRectangle(x, y, width, height, radius float32, color Color) {
+	DrawRoundedRectangleEx(x, y, width, height, radius, radius, radius, radius, color)
+}
+
+// Draw rectangle with a radius per corner
+// Radii are clamped to half the width and height.
+func DrawRoundedRectangleEx(x, y, width, height, topLeft, topRight, bottomRight, bottomLeft float32, color Color) {
+	points := roundedRectPoints(x, y, width, height, [4]float32{topLeft, topRight, bottomRight, bottomLeft})
+	drawFan(Vector2{x + width/2, y + height/2}, points, true, color, color)
+}
+
+// Draw rounded rectangle outline, inside the rectangle like DrawRectangleOutlineEx
+func DrawRoundedRectangleOutline(x, y, width, height, radius, thickness float32, color Color) {
+	if thickness*2 >= width || thickness*2 >= height {
+		DrawRoundedRectangle(x, y, width, height, radius, color)
+		return
+	}
+	h := thickness / 2
+	r := max(radius-h, 0)
+	points := roundedRectPoints(x+h, y+h, width-thickness, height-thickness, [4]float32{r, r, r, r})
+	DrawPolylineClosed(points, StrokeStyle{Thickness: thickness}, color)
+}
 
-	vertices := []float32{
-		glX, glY, color.R, color.G, color.B, // top-left
-		glX + glW, glY, color.R, color.G, color.B, // top-right
-		glX + glW, glY - glH, color.R, color.G, color.B, // bottom-right
-		glX, glY - glH, color.R, color.G, color.B, // bottom-left
-		glX, glY, color.R, color.G, color.B, // back to start
+// Draw polygon with equal sides, rotation in degrees
+func DrawRegularPolygon(centerX, centerY float32, sides int, radius, rotation float32, color Color) {
+	if sides < 3 {
+		return
 	}
+	drawFan(Vector2{centerX, centerY}, regularPolygonPoints(centerX, centerY, sides, radius, rotation), true, color, color)
+}
+
+// Draw outline of a polygon with equal sides, centered on its edges
+func DrawRegularPolygonOutline(centerX, centerY float32, sides int, radius, rotation, thickness float32, color Color) {
+	if sides < 3 {
+		return
+	}
+	DrawPolylineClosed(regularPolygonPoints(centerX, centerY, sides, radius, rotation), StrokeStyle{Thickness: thickness}, color)
+}
+
+// Draw any simple polygon, convex or concave
+// Points go around the edge in either direction. Edges that cross
+// each other aren't supported and may leave parts unfilled.
+func DrawPolygon(points []Vector2, color Color) {
+	indices := triangulate(points)
+	if len(indices) == 0 {
+		return
+	}
+	vertices := make([]float32, 0, len(points)*6)
+	for _, p := range points {
+		vertices = appendShapeVertex(vertices, p.X, p.Y, color)
+	}
+	drawShape(vertices, indices, Triangles)
+}
 
-	drawVertices(vertices, 5, gl.LINE_STRIP)
+// Fill triangles from center to each edge point
+// closed joins the last point back to the first.
+func drawFan(center Vector2, points []Vector2, closed bool, inner, outer Color) {
+	if len(points) < 2 {
+		return
+	}
+	vertices := make([]float32, 0, (len(points)+1)*6)
+	vertices = appendShapeVertex(vertices, center.X, center.Y, inner)
+	for _, p := range points {
+		vertices = appendShapeVertex(vertices, p.X, p.Y, outer)
+	}
+
+	n := uint32(len(points))
+	indices := make([]uint32, 0, n*3)
+	for i := uint32(1); i < n; i++ {
+		indices = append(indices, 0, i, i+1)
+	}
+	if closed {
+		indices = append(indices, 0, n, 1)
+	}
+	drawShape(vertices, indices, Triangles)
+}
+
+// Points along an ellipse from startAngle to endAngle, both ends included
+// Angles are in degrees, positive counter-clockwise on screen.
+func ellipsePoints(centerX, centerY, radiusX, radiusY, startAngle, endAngle float32) []Vector2 {
+	sweep := (endAngle - startAngle) * math.Pi / 180
+	segments := arcSegments(max(abs32(radiusX), abs32(radiusY)), abs32(sweep))
+	points := make([]Vector2, segments+1)
+	for i := range points {
+		angle := float64(startAngle*math.Pi/180 + sweep*float32(i)/float32(segments))
+		points[i] = Vector2{
+			centerX + radiusX*float32(math.Cos(angle)),
+			centerY - radiusY*float32(math.Sin(angle)),
+		}
+	}
+	return points
+}
+
+// Outline of a rounded rectangle, clockwise from the top-left corner
+func roundedRectPoints(x, y, width, height float32, radii [4]float32) []Vector2 {
+	limit := min(width, height) / 2
+	corners := [4]struct {
+		cx, cy, from float32
+	}{
+		{x, y, 180},                // top-left
+		{x + width, y, 90},         // top-right
+		{x + width, y + height, 0}, // bottom-right
+		{x, y + height, -90},       // bottom-left
+	}
+	signs := [4][2]float32{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+
+	var points []Vector2
+	for i, c := range corners {
+		r := min(max(radii[i], 0), limit)
+		if r == 0 {
+			points = append(points, Vector2{c.cx, c.cy})
+			continue
+		}
+		cx, cy := c.cx+signs[i][0]*r, c.cy+signs[i][1]*r
+		points = append(points, ellipsePoints(cx, cy, r, r, c.from, c.from-90)...)
+	}
+	return points
+}
+
+// Corners of a regular polygon, the first one at the rotation angle
+func regularPolygonPoints(centerX, centerY float32, sides int, radius, rotation float32) []Vector2 {
+	points := make([]Vector2, sides)
+	for i := range points {
+		angle := float64(rotation)*math.Pi/180 + float64(i)*2*math.Pi/float64(sides)
+		points[i] = Vector2{
+			centerX + radius*float32(math.Cos(angle)),
+			centerY - radius*float32(math.Sin(angle)),
+		}
+	}
+	return points
+}
+
+// Number of segments to keep an arc within a quarter pixel of a true circle
+// The radius is measured on screen, so zoomed in curves stay smooth
+// and small or zoomed out ones don't waste vertices.
+func arcSegments(radius, angle float32) int {
+	const maxError = 0.25
+	radius *= screenScale()
+	step := float32(math.Pi / 4)
+	if radius > maxError {
+		step = max(2*float32(math.Acos(float64(1-maxError/radius))), 0.01)
+	}
+	segments := int(ceil32(angle / step))
+	return max(segments, 1)
+}
+
+// Draw rectangle outline
+func DrawRectangleOutline(x, y, width, height float32, color Color) {
+	DrawRectangleOutlineEx(x, y, width, height, 1, color)
 }
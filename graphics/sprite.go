@@ -0,0 +1,346 @@
+package graphics
+
+// SpriteSheet is an image cut into frames
+// Frames are in image pixels from the top-left corner.
+type SpriteSheet struct {
+	Image  *Image
+	Frames []Rectangle
+	Info   []FrameInfo // optional, filled in by the sheet loaders
+	Slices []Slice     // named areas from Aseprite
+}
+
+// FrameInfo is what exported sheets know about a frame
+// Trimmed frames only store their visible part, which sits at Offset
+// inside the original SourceSize sprite.
+type FrameInfo struct {
+	Name       string
+	Offset     Vector2 // trimmed part position in the sprite
+	SourceSize Vector2 // sprite size before trimming
+	Pivot      Vector2 // origin in sprite pixels, rotation turns around it
+	Rotated    bool    // stored turned 90 degrees clockwise in the image
+}
+
+// NewSpriteSheet creates a sheet with the given frame rectangles
+func NewSpriteSheet(img *Image, frames []Rectangle) *SpriteSheet {
+	return &SpriteSheet{Image: img, Frames: frames}
+}
+
+// NewSpriteSheetGrid cuts an image into equal frames, left to right then top to bottom
+// margin is the border around the grid and spacing the gap between frames.
+func NewSpriteSheetGrid(img *Image, frameWidth, frameHeight, margin, spacing int) *SpriteSheet {
+	sheet := &SpriteSheet{Image: img}
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return sheet
+	}
+	for y := margin; y+frameHeight <= int(img.Height)-margin; y += frameHeight + spacing {
+		for x := margin; x+frameWidth <= int(img.Width)-margin; x += frameWidth + spacing {
+			sheet.Frames = append(sheet.Frames, Rect(float32(x), float32(y), float32(frameWidth), float32(frameHeight)))
+		}
+	}
+	return sheet
+}
+
+// Len returns the number of frames
+func (s *SpriteSheet) Len() int {
+	return len(s.Frames)
+}
+
+// DrawFrame draws a frame at its own size
+func (s *SpriteSheet) DrawFrame(frame int, x, y float32) {
+	s.DrawFrameEx(frame, DrawOptions{X: x, Y: y})
+}
+
+// FrameSize returns the size of a frame before trimming
+func (s *SpriteSheet) FrameSize(frame int) Vector2 {
+	if frame < 0 || frame >= len(s.Frames) {
+		return Vector2{}
+	}
+	if frame < len(s.Info) && s.Info[frame].SourceSize != (Vector2{}) {
+		return s.Info[frame].SourceSize
+	}
+	return Vector2{s.Frames[frame].Width, s.Frames[frame].Height}
+}
+
+// FrameIndex returns the frame with the given name, -1 if there is none
+func (s *SpriteSheet) FrameIndex(name string) int {
+	for i, info := range s.Info {
+		if info.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DrawFrameEx draws a frame with options, the source rect comes from the frame
+// Width and Height default to the frame size. X and Y place the top-left
+// corner of the untrimmed sprite, and frames with info turn around their pivot.
+func (s *SpriteSheet) DrawFrameEx(frame int, opts DrawOptions) {
+	if frame < 0 || frame >= len(s.Frames) || s.Image == nil {
+		return
+	}
+	src := s.Frames[frame]
+	if frame >= len(s.Info) {
+		drawRegion(s.Image, src, opts)
+		return
+	}
+
+	info := s.Info[frame]
+	size := s.FrameSize(frame)
+	if opts.Width == 0 {
+		opts.Width = size.X
+	}
+	if opts.Height == 0 {
+		opts.Height = size.Y
+	}
+	sx, sy := opts.Width/size.X, opts.Height/size.Y
+
+	// Screen size of the trimmed part the right way up
+	w, h := src.Width, src.Height
+	if info.Rotated {
+		w, h = h, w
+	}
+	w, h = w*sx, h*sy
+
+	// Its center, relative to the pivot and turned around it
+	cx := (info.Offset.X-info.Pivot.X)*sx + w/2
+	cy := (info.Offset.Y-info.Pivot.Y)*sy + h/2
+	cx, cy = MatrixRotate(opts.Rotation).Apply(cx, cy)
+	cx += opts.X + info.Pivot.X*sx
+	cy += opts.Y + info.Pivot.Y*sy
+
+	if info.Rotated {
+		// Draw it as stored and turn it back
+		w, h = h, w
+		opts.Rotation += 90
+	}
+	region := regionOptions(s.Image, src, Rect(cx-w/2, cy-h/2, w, h), opts.Tint)
+	region.Rotation = opts.Rotation
+	DrawImageEx(s.Image, region)
+}
+
+// Slice is a named area of a sprite, like a hitbox, from Aseprite
+// It can change over the animation, each key holds from its frame on.
+type Slice struct {
+	Name string
+	Keys []SliceKey
+}
+
+// SliceKey is a slice's shape from a frame on
+// Bounds, Center and Pivot are in sprite pixels.
+type SliceKey struct {
+	Frame    int
+	Bounds   Rectangle
+	Center   Rectangle // nine-patch center, zero if not set
+	Pivot    Vector2   // relative to Bounds, only if HasPivot
+	HasPivot bool
+}
+
+// Slice returns the named slice's key for a frame
+func (s *SpriteSheet) Slice(name string, frame int) (SliceKey, bool) {
+	for _, slice := range s.Slices {
+		if slice.Name != name {
+			continue
+		}
+		key, found := SliceKey{}, false
+		for _, k := range slice.Keys {
+			if k.Frame <= frame && (!found || k.Frame >= key.Frame) {
+				key, found = k, true
+			}
+		}
+		return key, found
+	}
+	return SliceKey{}, false
+}
+
+// PlayMode is what an animation does when it reaches its last frame
+type PlayMode int
+
+const (
+	PlayLoop     PlayMode = iota // start over from the first frame
+	PlayPingPong                 // play backwards, then forwards again
+	PlayOnce                     // stop on the last frame
+)
+
+// AnimationClip is a sequence of sheet frames
+type AnimationClip struct {
+	Frames    []int     // frame numbers in the sheet
+	Durations []float64 // seconds per frame, the last one is used for the rest
+	Mode      PlayMode
+}
+
+// Frame rate of clips that don't give one
+const defaultClipFPS = 10
+
+// NewClip creates a clip that plays frames at a fixed rate
+// fps <= 0 plays at the default rate of 10 frames per second.
+func NewClip(frames []int, fps float64, mode PlayMode) AnimationClip {
+	if fps <= 0 {
+		fps = defaultClipFPS
+	}
+	return AnimationClip{Frames: frames, Durations: []float64{1 / fps}, Mode: mode}
+}
+
+// Seconds frame i stays on screen
+func (c *AnimationClip) duration(i int) float64 {
+	if len(c.Durations) == 0 {
+		return 1.0 / defaultClipFPS
+	}
+	d := c.Durations[min(i, len(c.Durations)-1)]
+	return max(d, 0.001)
+}
+
+// AnimatedSprite plays named clips from a sprite sheet
+// Call Update once per frame to advance it with GetDeltaTime.
+type AnimatedSprite struct {
+	Sheet *SpriteSheet
+	Clips map[string]*AnimationClip
+	Speed float64 // playback rate, 1 is normal speed
+
+	OnLoop   func(clip string) // a loop or ping-pong clip went round once
+	OnFinish func(clip string) // a once clip reached its end
+
+	current   string
+	index     int // position in the clip's frames
+	direction int // 1 forwards, -1 backwards in ping-pong
+	timer     float64
+	playing   bool
+	finished  bool
+}
+
+// NewAnimatedSprite creates an animated sprite with no clips
+func NewAnimatedSprite(sheet *SpriteSheet) *AnimatedSprite {
+	return &AnimatedSprite{
+		Sheet:     sheet,
+		Clips:     make(map[string]*AnimationClip),
+		Speed:     1,
+		direction: 1,
+	}
+}
+
+// AddClip adds or replaces a named clip
+func (a *AnimatedSprite) AddClip(name string, clip AnimationClip) {
+	a.Clips[name] = &clip
+}
+
+// Play switches to a clip and starts it from the beginning
+// Playing the clip that is already running just resumes it.
+func (a *AnimatedSprite) Play(name string) {
+	if name == a.current && !a.finished {
+		a.playing = true
+		return
+	}
+	a.current = name
+	a.Reset()
+}
+
+// Reset starts the current clip over
+func (a *AnimatedSprite) Reset() {
+	a.index, a.timer, a.direction = 0, 0, 1
+	a.playing, a.finished = true, false
+}
+
+// Pause stops the animation on its current frame
+func (a *AnimatedSprite) Pause() {
+	a.playing = false
+}
+
+// Resume continues a paused animation
+func (a *AnimatedSprite) Resume() {
+	if !a.finished {
+		a.playing = true
+	}
+}
+
+// IsPlaying reports whether the animation is advancing
+func (a *AnimatedSprite) IsPlaying() bool {
+	return a.playing
+}
+
+// IsFinished reports whether a once clip has reached its end
+func (a *AnimatedSprite) IsFinished() bool {
+	return a.finished
+}
+
+// CurrentClip returns the name of the clip being played
+func (a *AnimatedSprite) CurrentClip() string {
+	return a.current
+}
+
+// CurrentFrame returns the sheet frame to show, -1 without a clip
+func (a *AnimatedSprite) CurrentFrame() int {
+	clip := a.Clips[a.current]
+	if clip == nil || len(clip.Frames) == 0 {
+		return -1
+	}
+	return clip.Frames[min(a.index, len(clip.Frames)-1)]
+}
+
+// Update advances the animation by the last frame's delta time
+func (a *AnimatedSprite) Update() {
+	a.UpdateBy(GetDeltaTime())
+}
+
+// UpdateBy advances the animation by dt seconds
+func (a *AnimatedSprite) UpdateBy(dt float64) {
+	clip := a.Clips[a.current]
+	if !a.playing || clip == nil || len(clip.Frames) == 0 {
+		return
+	}
+	a.timer += dt * a.Speed
+	for a.playing && a.Clips[a.current] == clip && a.timer >= clip.duration(a.index) {
+		a.timer -= clip.duration(a.index)
+		a.step(clip)
+	}
+}
+
+// Move to the next frame of the clip
+func (a *AnimatedSprite) step(clip *AnimationClip) {
+	last := len(clip.Frames) - 1
+	switch clip.Mode {
+	case PlayOnce:
+		if a.index >= last {
+			a.playing, a.finished, a.timer = false, true, 0
+			if a.OnFinish != nil {
+				a.OnFinish(a.current)
+			}
+			return
+		}
+		a.index++
+	case PlayPingPong:
+		if last == 0 {
+			a.looped()
+			return
+		}
+		if a.index+a.direction < 0 || a.index+a.direction > last {
+			a.direction = -a.direction
+		}
+		a.index += a.direction
+		if a.index == 0 {
+			a.looped()
+		}
+	default:
+		a.index++
+		if a.index > last {
+			a.index = 0
+			a.looped()
+		}
+	}
+}
+
+func (a *AnimatedSprite) looped() {
+	if a.OnLoop != nil {
+		a.OnLoop(a.current)
+	}
+}
+
+// Draw draws the current frame at its own size
+func (a *AnimatedSprite) Draw(x, y float32) {
+	a.DrawEx(DrawOptions{X: x, Y: y})
+}
+
+// DrawEx draws the current frame with options, see SpriteSheet.DrawFrameEx
+func (a *AnimatedSprite) DrawEx(opts DrawOptions) {
+	if a.Sheet != nil {
+		a.Sheet.DrawFrameEx(a.CurrentFrame(), opts)
+	}
+}
@@ -0,0 +1,368 @@
+package graphics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON sheet as written by Aseprite and TexturePacker
+// Both write frames either as an object keyed by name or as an array.
+type sheetJSON struct {
+	Frames     json.RawMessage     `json:"frames"`
+	Animations map[string][]string `json:"animations"` // TexturePacker, pixi style
+	Meta       struct {
+		Image     string       `json:"image"`
+		FrameTags []sheetTag   `json:"frameTags"`
+		Slices    []sheetSlice `json:"slices"`
+	} `json:"meta"`
+}
+
+type sheetFrame struct {
+	Filename         string    `json:"filename"`
+	Frame            sheetRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize sheetRect `json:"spriteSourceSize"`
+	SourceSize       sheetSize `json:"sourceSize"`
+	Pivot            *sheetXY  `json:"pivot"`  // 0 to 1 of the source size
+	Anchor           *sheetXY  `json:"anchor"` // same thing, pixi exports
+	Duration         float64   `json:"duration"`
+}
+
+type sheetTag struct {
+	Name      string          `json:"name"`
+	From      int             `json:"from"`
+	To        int             `json:"to"`
+	Direction string          `json:"direction"`
+	Repeat    json.RawMessage `json:"repeat"` // a string in Aseprite files
+}
+
+type sheetSlice struct {
+	Name string `json:"name"`
+	Keys []struct {
+		Frame  int        `json:"frame"`
+		Bounds sheetRect  `json:"bounds"`
+		Center *sheetRect `json:"center"`
+		Pivot  *sheetXY   `json:"pivot"`
+	} `json:"keys"`
+}
+
+type sheetRect struct {
+	X, Y, W, H float32
+}
+
+type sheetSize struct {
+	W, H float32
+}
+
+type sheetXY struct {
+	X, Y float32
+}
+
+func (r sheetRect) rect() Rectangle {
+	return Rect(r.X, r.Y, r.W, r.H)
+}
+
+// LoadAseprite loads a sheet exported from Aseprite with its JSON data
+// Each tag becomes a clip with the tag's name, direction and frame
+// durations, and the first tag starts playing. Without tags there is
+// one "default" clip with every frame. Slices end up in Sheet.Slices.
+func LoadAseprite(jsonPath string) (*AnimatedSprite, error) {
+	data, frames, err := loadSheetJSON(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := loadSheetImage(jsonPath, data, frames)
+	if err != nil {
+		return nil, err
+	}
+	for _, sl := range data.Meta.Slices {
+		slice := Slice{Name: sl.Name}
+		for _, k := range sl.Keys {
+			key := SliceKey{Frame: k.Frame, Bounds: k.Bounds.rect()}
+			if k.Center != nil {
+				key.Center = k.Center.rect()
+			}
+			if k.Pivot != nil {
+				key.Pivot, key.HasPivot = Vec2(k.Pivot.X, k.Pivot.Y), true
+			}
+			slice.Keys = append(slice.Keys, key)
+		}
+		sheet.Slices = append(sheet.Slices, slice)
+	}
+
+	sprite := NewAnimatedSprite(sheet)
+	first := ""
+	for _, tag := range data.Meta.FrameTags {
+		clip, err := tagClip(tag, frames)
+		if err != nil {
+			sheet.Image.Delete()
+			return nil, fmt.Errorf("%s: %w", jsonPath, err)
+		}
+		sprite.AddClip(tag.Name, clip)
+		if first == "" {
+			first = tag.Name
+		}
+	}
+	return startSprite(sprite, first, frames, 10), nil
+}
+
+// LoadTexturePacker loads a sheet exported from TexturePacker in its JSON
+// (hash or array) format. Clips come from the "animations" list when the
+// export has one, otherwise numbered frames like walk_01.png, walk_02.png
+// become a "walk" clip. TexturePacker stores no timing, so clips play at fps.
+func LoadTexturePacker(jsonPath string, fps float64) (*AnimatedSprite, error) {
+	data, frames, err := loadSheetJSON(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := loadSheetImage(jsonPath, data, frames)
+	if err != nil {
+		return nil, err
+	}
+	if fps <= 0 {
+		fps = defaultClipFPS
+	}
+
+	sprite := NewAnimatedSprite(sheet)
+	clips := data.Animations
+	if len(clips) == 0 {
+		clips = numberedClips(frames)
+	}
+	names := make([]string, 0, len(clips))
+	for name, frameNames := range clips {
+		var indices []int
+		for _, frameName := range frameNames {
+			i := sheet.FrameIndex(frameName)
+			if i < 0 {
+				sheet.Image.Delete()
+				return nil, fmt.Errorf("%s: animation %q uses missing frame %q", jsonPath, name, frameName)
+			}
+			indices = append(indices, i)
+		}
+		sprite.AddClip(name, NewClip(indices, fps, PlayLoop))
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	first := ""
+	if len(names) > 0 {
+		first = names[0]
+	}
+	return startSprite(sprite, first, frames, fps), nil
+}
+
+// Clone returns a sprite that shares the sheet and clips but plays on its own
+func (a *AnimatedSprite) Clone() *AnimatedSprite {
+	clone := *a
+	return &clone
+}
+
+// Read the JSON file and its frames in file order
+func loadSheetJSON(jsonPath string) (*sheetJSON, []sheetFrame, error) {
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var data sheetJSON
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", jsonPath, err)
+	}
+	frames, err := decodeSheetFrames(data.Frames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: frames: %w", jsonPath, err)
+	}
+	if len(frames) == 0 {
+		return nil, nil, fmt.Errorf("%s: no frames", jsonPath)
+	}
+	return &data, frames, nil
+}
+
+// Frames are an array, or an object whose key order is the frame order
+func decodeSheetFrames(raw json.RawMessage) ([]sheetFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var frames []sheetFrame
+	if raw[0] == '[' {
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame sheetFrame
+		if err := dec.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename = key.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// Load the sheet's image and set up its frames
+func loadSheetImage(jsonPath string, data *sheetJSON, frames []sheetFrame) (*SpriteSheet, error) {
+	imagePath := data.Meta.Image
+	if imagePath == "" {
+		imagePath = strings.TrimSuffix(filepath.Base(jsonPath), filepath.Ext(jsonPath)) + ".png"
+	}
+	if !filepath.IsAbs(imagePath) {
+		imagePath = filepath.Join(filepath.Dir(jsonPath), imagePath)
+	}
+	img, err := LoadImage(imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &SpriteSheet{Image: img}
+	for _, f := range frames {
+		src := f.Frame.rect()
+		if f.Rotated {
+			// The frame size is the sprite's, turned on its side in the image
+			src.Width, src.Height = src.Height, src.Width
+		}
+		info := FrameInfo{
+			Name:       f.Filename,
+			SourceSize: Vec2(f.SourceSize.W, f.SourceSize.H),
+			Rotated:    f.Rotated,
+		}
+		if info.SourceSize == (Vector2{}) {
+			info.SourceSize = Vec2(f.Frame.W, f.Frame.H)
+		}
+		if f.Trimmed {
+			info.Offset = Vec2(f.SpriteSourceSize.X, f.SpriteSourceSize.Y)
+		}
+		pivot := sheetXY{0.5, 0.5}
+		if f.Pivot != nil {
+			pivot = *f.Pivot
+		} else if f.Anchor != nil {
+			pivot = *f.Anchor
+		}
+		info.Pivot = Vec2(pivot.X*info.SourceSize.X, pivot.Y*info.SourceSize.Y)
+
+		sheet.Frames = append(sheet.Frames, src)
+		sheet.Info = append(sheet.Info, info)
+	}
+	return sheet, nil
+}
+
+// Build the clip for an Aseprite tag
+func tagClip(tag sheetTag, frames []sheetFrame) (AnimationClip, error) {
+	if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+		return AnimationClip{}, fmt.Errorf("tag %q has frames %d to %d out of %d", tag.Name, tag.From, tag.To, len(frames))
+	}
+	var indices []int
+	for i := tag.From; i <= tag.To; i++ {
+		indices = append(indices, i)
+	}
+	mode := PlayLoop
+	switch tag.Direction {
+	case "reverse":
+		slices.Reverse(indices)
+	case "pingpong":
+		mode = PlayPingPong
+	case "pingpong_reverse":
+		slices.Reverse(indices)
+		mode = PlayPingPong
+	}
+
+	// A repeat count plays the tag that many times and stops,
+	// each way counts once in ping-pong
+	if repeat := tagRepeat(tag.Repeat); repeat > 0 {
+		pass := indices
+		for i := 1; i < repeat; i++ {
+			if mode == PlayPingPong {
+				pass = append([]int(nil), pass...)
+				slices.Reverse(pass)
+				indices = append(indices, pass[1:]...)
+			} else {
+				indices = append(indices, pass...)
+			}
+		}
+		mode = PlayOnce
+	}
+
+	clip := AnimationClip{Frames: indices, Mode: mode}
+	for _, i := range indices {
+		clip.Durations = append(clip.Durations, frameSeconds(frames[i], 1.0/defaultClipFPS))
+	}
+	return clip, nil
+}
+
+// Aseprite writes the repeat count as a string, be lenient about numbers
+func tagRepeat(raw json.RawMessage) int {
+	n, err := strconv.Atoi(strings.Trim(string(raw), `"`))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Seconds a frame stays on screen, its JSON duration is in milliseconds
+func frameSeconds(f sheetFrame, fallback float64) float64 {
+	if f.Duration > 0 {
+		return f.Duration / 1000
+	}
+	return fallback
+}
+
+// Group frames named like walk_01.png, walk_02.png by their prefix
+func numberedClips(frames []sheetFrame) map[string][]string {
+	type numbered struct {
+		name   string
+		number int
+	}
+	groups := make(map[string][]numbered)
+	for _, f := range frames {
+		base := strings.TrimSuffix(f.Filename, filepath.Ext(f.Filename))
+		digits := len(base)
+		for digits > 0 && base[digits-1] >= '0' && base[digits-1] <= '9' {
+			digits--
+		}
+		if digits == len(base) {
+			continue
+		}
+		number, _ := strconv.Atoi(base[digits:])
+		prefix := strings.TrimRight(base[:digits], "_-. ")
+		groups[prefix] = append(groups[prefix], numbered{f.Filename, number})
+	}
+
+	clips := make(map[string][]string, len(groups))
+	for prefix, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].number < group[j].number })
+		for _, n := range group {
+			clips[prefix] = append(clips[prefix], n.name)
+		}
+	}
+	return clips
+}
+
+// Fall back to a clip with every frame and start playing
+func startSprite(sprite *AnimatedSprite, first string, frames []sheetFrame, fps float64) *AnimatedSprite {
+	if first == "" {
+		clip := AnimationClip{Mode: PlayLoop}
+		for i, f := range frames {
+			clip.Frames = append(clip.Frames, i)
+			clip.Durations = append(clip.Durations, frameSeconds(f, 1/fps))
+		}
+		first = "default"
+		sprite.AddClip(first, clip)
+	}
+	sprite.Play(first)
+	return sprite
+}
@@ -0,0 +1,406 @@
+package graphics
+
+import (
+	"math"
+)
+
+// LineJoin is the shape drawn where two segments of a stroke meet
+type LineJoin int
+
+const (
+	JoinMiter LineJoin = iota // sharp corner, bevelled past the miter limit
+	JoinRound                 // rounded corner
+	JoinBevel                 // corner cut flat
+)
+
+// LineCap is the shape drawn at the open ends of a stroke
+type LineCap int
+
+const (
+	CapButt   LineCap = iota // ends exactly at the end point
+	CapRound                 // half circle around the end point
+	CapSquare                // half the thickness past the end point
+)
+
+// StrokeStyle describes how DrawPolyline draws its lines
+type StrokeStyle struct {
+	Thickness  float32   // line width, 0 means 1
+	Join       LineJoin  // how segments meet
+	Cap        LineCap   // how open ends and dashes end
+	MiterLimit float32   // longest miter as a multiple of the thickness, 0 means 4
+	Dash       []float32 // dash and gap lengths, alternating, empty for a solid line
+	DashOffset float32   // distance into the dash pattern where the line starts
+}
+
+// Draw line with a thickness
+func DrawLineEx(x1, y1, x2, y2, thickness float32, color Color) {
+	drawLineQuad(x1, y1, x2, y2, thickness, color, color)
+}
+
+// Draw connected lines through the points
+func DrawPolyline(points []Vector2, style StrokeStyle, color Color) {
+	strokePoints(points, false, style, color)
+}
+
+// Draw connected lines through the points and back to the first one
+func DrawPolylineClosed(points []Vector2, style StrokeStyle, color Color) {
+	strokePoints(points, true, style, color)
+}
+
+// Draw circle outline centered on the radius
+func DrawCircleOutline(centerX, centerY, radius, thickness float32, color Color) {
+	DrawEllipseOutline(centerX, centerY, radius, radius, thickness, color)
+}
+
+// Draw triangle outline centered on its edges
+func DrawTriangleOutline(x1, y1, x2, y2, x3, y3, thickness float32, color Color) {
+	points := []Vector2{{x1, y1}, {x2, y2}, {x3, y3}}
+	DrawPolylineClosed(points, StrokeStyle{Thickness: thickness}, color)
+}
+
+// Draw rectangle outline with a thickness
+// The outline stays inside the rectangle, so it covers the same
+// area as DrawRectangle with the middle left out.
+func DrawRectangleOutlineEx(x, y, width, height, thickness float32, color Color) {
+	if thickness*2 >= width || thickness*2 >= height {
+		DrawRectangle(x, y, width, height, color)
+		return
+	}
+	h := thickness / 2
+	points := []Vector2{
+		{x + h, y + h},
+		{x + width - h, y + h},
+		{x + width - h, y + height - h},
+		{x + h, y + height - h},
+	}
+	DrawPolylineClosed(points, StrokeStyle{Thickness: thickness}, color)
+}
+
+// Draw a line as a quad, fading from color1 to color2
+func drawLineQuad(x1, y1, x2, y2, thickness float32, color1, color2 Color) {
+	d := Vector2{x2 - x1, y2 - y1}.Normalize()
+	if d == (Vector2{}) {
+		return
+	}
+	n := Vector2{-d.Y, d.X}.Scale(thickness / 2)
+
+	vertices := make([]float32, 0, 4*6)
+	vertices = appendShapeVertex(vertices, x1+n.X, y1+n.Y, color1)
+	vertices = appendShapeVertex(vertices, x2+n.X, y2+n.Y, color2)
+	vertices = appendShapeVertex(vertices, x2-n.X, y2-n.Y, color2)
+	vertices = appendShapeVertex(vertices, x1-n.X, y1-n.Y, color1)
+	drawShape(vertices, []uint32{0, 1, 2, 2, 3, 0}, Triangles)
+}
+
+// Stroke a polyline, split into dashes if the style has them
+func strokePoints(points []Vector2, closed bool, style StrokeStyle, color Color) {
+	if style.Thickness <= 0 {
+		style.Thickness = 1
+	}
+	if style.MiterLimit <= 0 {
+		style.MiterLimit = 4
+	}
+
+	s := &strokeBuilder{color: color, style: style}
+	if dashes := dashPolyline(points, closed, style.Dash, style.DashOffset); dashes != nil {
+		for _, dash := range dashes {
+			s.polyline(dash, false)
+		}
+	} else {
+		s.polyline(points, closed)
+	}
+	if len(s.indices) > 0 {
+		drawShape(s.vertices, s.indices, Triangles)
+	}
+}
+
+// strokeBuilder collects the triangles of a stroke
+// Neighbouring pieces share their vertices, so nothing is covered
+// twice and transparent strokes blend evenly.
+type strokeBuilder struct {
+	vertices []float32
+	indices  []uint32
+	color    Color
+	style    StrokeStyle
+}
+
+// Add a vertex and return its index
+func (s *strokeBuilder) point(p Vector2) uint32 {
+	i := uint32(len(s.vertices) / 6)
+	s.vertices = appendShapeVertex(s.vertices, p.X, p.Y, s.color)
+	return i
+}
+
+func (s *strokeBuilder) triangle(a, b, c uint32) {
+	if a == b || b == c || a == c {
+		return
+	}
+	s.indices = append(s.indices, a, b, c)
+}
+
+// Fan triangles from center along an arc around pivot, from first to last
+// The arc starts at angle from and turns by sweep (radians).
+func (s *strokeBuilder) arc(center uint32, pivot Vector2, radius, from, sweep float32, first, last uint32) {
+	segments := arcSegments(radius, abs32(sweep))
+	prev := first
+	for k := 1; k < segments; k++ {
+		angle := float64(from + sweep*float32(k)/float32(segments))
+		p := s.point(Vector2{
+			pivot.X + radius*float32(math.Cos(angle)),
+			pivot.Y + radius*float32(math.Sin(angle)),
+		})
+		s.triangle(center, prev, p)
+		prev = p
+	}
+	s.triangle(center, prev, last)
+}
+
+// Stroke one polyline
+func (s *strokeBuilder) polyline(points []Vector2, closed bool) {
+	pts := make([]Vector2, 0, len(points))
+	for _, p := range points {
+		if len(pts) == 0 || p.Sub(pts[len(pts)-1]).Length() > 1e-4 {
+			pts = append(pts, p)
+		}
+	}
+	if closed && len(pts) > 2 && pts[0].Sub(pts[len(pts)-1]).Length() <= 1e-4 {
+		pts = pts[:len(pts)-1]
+	}
+	if closed && len(pts) < 3 {
+		closed = false
+	}
+	if len(pts) == 1 {
+		s.dot(pts[0])
+		return
+	}
+	if len(pts) < 2 {
+		return
+	}
+
+	segments := len(pts) - 1
+	if closed {
+		segments = len(pts)
+	}
+	dirs := make([]Vector2, segments)
+	lengths := make([]float32, segments)
+	for i := range dirs {
+		d := pts[(i+1)%len(pts)].Sub(pts[i])
+		dirs[i], lengths[i] = d.Normalize(), d.Length()
+	}
+
+	// Left and right vertex ending the segment coming into each point
+	// and starting the segment going out of it
+	in := make([][2]uint32, len(pts))
+	out := make([][2]uint32, len(pts))
+	for j := range pts {
+		switch {
+		case !closed && j == 0:
+			out[j] = s.cap(pts[j], dirs[0], true)
+		case !closed && j == len(pts)-1:
+			in[j] = s.cap(pts[j], dirs[segments-1], false)
+		default:
+			prev := (j - 1 + segments) % segments
+			in[j], out[j] = s.join(pts[j], dirs[prev], dirs[j], min(lengths[prev], lengths[j]))
+		}
+	}
+
+	for i := 0; i < segments; i++ {
+		start, end := out[i], in[(i+1)%len(pts)]
+		s.triangle(start[0], start[1], end[1])
+		s.triangle(end[1], end[0], start[0])
+	}
+}
+
+// Build an end of an open line and return its left and right vertices
+func (s *strokeBuilder) cap(p, d Vector2, start bool) [2]uint32 {
+	h := s.style.Thickness / 2
+	n := Vector2{-d.Y, d.X}
+	base := p
+	if s.style.Cap == CapSquare {
+		if start {
+			base = p.Sub(d.Scale(h))
+		} else {
+			base = p.Add(d.Scale(h))
+		}
+	}
+	left, right := s.point(base.Add(n.Scale(h))), s.point(base.Sub(n.Scale(h)))
+	if s.style.Cap == CapRound {
+		// Half circle behind the start or past the end, fanned from
+		// a corner so it shares its edge with the line
+		sweep := float32(math.Pi)
+		if !start {
+			sweep = -sweep
+		}
+		s.arc(left, p, h, angleOf(n), sweep, left, right)
+	}
+	return [2]uint32{left, right}
+}
+
+// Build the corner at p between directions d0 and d1
+// The inner side gets one shared vertex, the outer side gets the join.
+func (s *strokeBuilder) join(p, d0, d1 Vector2, shortest float32) (in, out [2]uint32) {
+	h := s.style.Thickness / 2
+	n0, n1 := Vector2{-d0.Y, d0.X}, Vector2{-d1.Y, d1.X}
+	cross, dot := d0.Cross(d1), d0.Dot(d1)
+
+	if abs32(cross) < 1e-6 && dot > 0 {
+		// Straight on, no corner
+		left, right := s.point(p.Add(n0.Scale(h))), s.point(p.Sub(n0.Scale(h)))
+		return [2]uint32{left, right}, [2]uint32{left, right}
+	}
+
+	// Turning clockwise on screen puts the left side on the inside
+	innerSide := float32(-1)
+	if cross > 0 {
+		innerSide = 1
+	}
+	outerSide := -innerSide
+
+	m := n0.Add(n1).Normalize()
+	cosHalf := m.Dot(n0)
+	sinHalf := abs32(m.Cross(n0))
+
+	// The inner corner can't go further back than the shorter segment
+	innerLen := float32(0)
+	if cosHalf > 1e-6 {
+		innerLen = h / cosHalf
+		if innerLen*sinHalf > shortest {
+			innerLen = shortest / sinHalf
+		}
+	}
+	inner := s.point(p.Add(m.Scale(innerLen * innerSide)))
+
+	var outer0, outer1 uint32
+	switch {
+	case s.style.Join == JoinMiter && cosHalf > 1e-6 && 1/cosHalf <= s.style.MiterLimit:
+		outer0 = s.point(p.Add(m.Scale(h / cosHalf * outerSide)))
+		outer1 = outer0
+	case s.style.Join == JoinRound:
+		o0, o1 := n0.Scale(h*outerSide), n1.Scale(h*outerSide)
+		outer0, outer1 = s.point(p.Add(o0)), s.point(p.Add(o1))
+		sweep := angleOf(o1) - angleOf(o0)
+		if dot < -0.9999 {
+			// Turning back, go round the front of the line
+			sweep = -math.Pi * outerSide
+		}
+		for sweep > math.Pi {
+			sweep -= 2 * math.Pi
+		}
+		for sweep < -math.Pi {
+			sweep += 2 * math.Pi
+		}
+		s.arc(inner, p, h, angleOf(o0), sweep, outer0, outer1)
+	default:
+		outer0 = s.point(p.Add(n0.Scale(h * outerSide)))
+		outer1 = s.point(p.Add(n1.Scale(h * outerSide)))
+		s.triangle(inner, outer0, outer1)
+	}
+
+	if innerSide > 0 {
+		return [2]uint32{inner, outer0}, [2]uint32{inner, outer1}
+	}
+	return [2]uint32{outer0, inner}, [2]uint32{outer1, inner}
+}
+
+// A zero length line, drawn only when the cap has a size
+func (s *strokeBuilder) dot(p Vector2) {
+	h := s.style.Thickness / 2
+	switch s.style.Cap {
+	case CapRound:
+		first := s.point(Vector2{p.X + h, p.Y})
+		s.arc(s.point(p), p, h, 0, 2*math.Pi, first, first)
+	case CapSquare:
+		a := s.point(Vector2{p.X - h, p.Y - h})
+		b := s.point(Vector2{p.X + h, p.Y - h})
+		c := s.point(Vector2{p.X + h, p.Y + h})
+		d := s.point(Vector2{p.X - h, p.Y + h})
+		s.triangle(a, b, c)
+		s.triangle(c, d, a)
+	}
+}
+
+// Cut a polyline into the "on" parts of a dash pattern
+// Returns nil when the pattern is empty or has no length.
+func dashPolyline(points []Vector2, closed bool, dash []float32, offset float32) [][]Vector2 {
+	pattern := dash
+	if len(pattern)%2 == 1 {
+		// Odd patterns repeat twice so dashes and gaps alternate
+		pattern = append(append([]float32{}, dash...), dash...)
+	}
+	var total float32
+	for _, d := range pattern {
+		total += max(d, 0)
+	}
+	if total <= 0 || len(points) < 2 {
+		return nil
+	}
+	if closed {
+		points = append(append([]Vector2{}, points...), points[0])
+	}
+
+	// Skip into the pattern by the offset
+	offset = float32(math.Mod(float64(offset), float64(total)))
+	if offset < 0 {
+		offset += total
+	}
+	index := 0
+	remaining := max(pattern[0], 0)
+	for offset > 0 {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		index++
+		remaining = max(pattern[index%len(pattern)], 0)
+	}
+
+	var dashes [][]Vector2
+	var current []Vector2
+	on := index%2 == 0
+	if on {
+		current = []Vector2{points[0]}
+	}
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		length := b.Sub(a).Length()
+		pos := float32(0)
+		for {
+			step := min(remaining, length-pos)
+			pos += step
+			remaining -= step
+			p := a.Lerp(b, pos/max(length, 1e-6))
+			if on {
+				current = append(current, p)
+			}
+			if remaining > 0 {
+				// Segment ended first, carry on with the next one
+				break
+			}
+			// Dash or gap finished
+			if on {
+				dashes = append(dashes, current)
+				current = nil
+			}
+			index++
+			remaining = max(pattern[index%len(pattern)], 0)
+			on = index%2 == 0
+			if on {
+				current = []Vector2{p}
+			}
+			if pos >= length {
+				// It finished on the segment's end, the next part starts on the next segment
+				break
+			}
+		}
+	}
+	if on && len(current) > 1 {
+		dashes = append(dashes, current)
+	}
+	return dashes
+}
+
+// Angle of a direction in radians
+func angleOf(v Vector2) float32 {
+	return float32(math.Atan2(float64(v.Y), float64(v.X)))
+}
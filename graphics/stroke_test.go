@@ -0,0 +1,56 @@
+package graphics
+
+import "testing"
+
+func TestDashPolyline(t *testing.T) {
+	line := []Vector2{{0, 0}, {100, 0}}
+	tests := []struct {
+		name   string
+		points []Vector2
+		closed bool
+		dash   []float32
+		offset float32
+		starts []float32 // x where each dash starts
+		on     float32   // length drawn in total
+	}{
+		{"dash and gap", line, false, []float32{10, 5}, 0, []float32{0, 15, 30, 45, 60, 75, 90}, 70},
+		{"offset into a dash", line, false, []float32{10, 5}, 5, []float32{0, 10, 25, 40, 55, 70, 85}, 65},
+		{"offset into a gap", line, false, []float32{10, 5}, 12, []float32{3, 18, 33, 48, 63, 78, 93}, 67},
+		{"negative offset", line, false, []float32{10, 5}, -5, []float32{5, 20, 35, 50, 65, 80, 95}, 65},
+		{"offset past the pattern", line, false, []float32{10, 5}, 20, []float32{0, 10, 25, 40, 55, 70, 85}, 65},
+		{"odd pattern of one", line, false, []float32{10}, 0, []float32{0, 20, 40, 60, 80}, 50},
+		{"odd pattern of three", line, false, []float32{10, 5, 5}, 0, []float32{0, 15, 30, 40, 55, 70, 80, 95}, 55},
+		{"closed square", square(0, 0, 10), true, []float32{15, 5}, 0, []float32{0, 10}, 30},
+	}
+	for _, tt := range tests {
+		dashes := dashPolyline(tt.points, tt.closed, tt.dash, tt.offset)
+		if len(dashes) != len(tt.starts) {
+			t.Errorf("%s: got %d dashes, want %d", tt.name, len(dashes), len(tt.starts))
+			continue
+		}
+		var on float32
+		for i, d := range dashes {
+			if !tt.closed && abs32(d[0].X-tt.starts[i]) > 1e-3 {
+				t.Errorf("%s: dash %d starts at %v, want %v", tt.name, i, d[0].X, tt.starts[i])
+			}
+			for j := 0; j+1 < len(d); j++ {
+				on += d[j+1].Sub(d[j]).Length()
+			}
+		}
+		if abs32(on-tt.on) > 1e-3 {
+			t.Errorf("%s: dashes are %v long in total, want %v", tt.name, on, tt.on)
+		}
+	}
+}
+
+func TestDashPolylineEmpty(t *testing.T) {
+	line := []Vector2{{0, 0}, {100, 0}}
+	for _, dash := range [][]float32{nil, {0, 0}, {-1, -2}} {
+		if dashes := dashPolyline(line, false, dash, 0); dashes != nil {
+			t.Errorf("pattern %v: got %d dashes, want none", dash, len(dashes))
+		}
+	}
+	if dashes := dashPolyline([]Vector2{{0, 0}}, false, []float32{1, 1}, 0); dashes != nil {
+		t.Errorf("single point: got %d dashes, want none", len(dashes))
+	}
+}
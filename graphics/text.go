@@ -1,13 +1,27 @@
 package graphics
 
+import (
+	"bytes"
+	_ "embed"
+	"image/png"
+)
+
+// Built into the binary so text works from any working directory
+//
+//go:embed font/font_atlas_bold.png
+var fontAtlasPNG []byte
+
 var fontAtlas *Image
 var charWidth, charHeight = 20, 24
 var charsPerRow = 16
 
 func loadFontAtlas() error {
-	var err error
-	fontAtlas, err = LoadImage("font/font_atlas_bold.png")
-	return err
+	img, err := png.Decode(bytes.NewReader(fontAtlasPNG))
+	if err != nil {
+		return err
+	}
+	fontAtlas = NewImageFromImage(img)
+	return nil
 }
 
 // DrawTextFromAtlas renders text using the font atlas (fixed-width).
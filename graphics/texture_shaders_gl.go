@@ -0,0 +1,46 @@
+//go:build !pixu_headless
+
+package graphics
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+func setupTextureShaders(pipe *pipeline) error {
+	program, err := linkProgram(textureVertexShaderSource, textureFragmentShaderSource)
+	if err != nil {
+		return err
+	}
+	pipe.program = program
+
+	// Set texture uniform
+	gl.UseProgram(pipe.program)
+	textureUniform := gl.GetUniformLocation(pipe.program, gl.Str("ourTexture\x00"))
+	gl.Uniform1i(textureUniform, 0) // Texture unit 0
+
+	pipe.transform = gl.GetUniformLocation(pipe.program, gl.Str("uTransform\x00"))
+	pipe.alphaCutoff = gl.GetUniformLocation(pipe.program, gl.Str("uAlphaCutoff\x00"))
+	return nil
+}
+
+func setupTextureBuffers(pipe *pipeline) {
+	gl.GenVertexArrays(1, &pipe.vao)
+	gl.GenBuffers(1, &pipe.vbo)
+	gl.GenBuffers(1, &pipe.ebo)
+
+	gl.BindVertexArray(pipe.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, pipe.vbo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, pipe.ebo)
+
+	// Position attribute (location 0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 8*4, nil)
+	gl.EnableVertexAttribArray(0)
+
+	// Texture coordinate attribute (location 1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 8*4, gl.PtrOffset(2*4))
+	gl.EnableVertexAttribArray(1)
+
+	// Color attribute (location 2)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, 8*4, gl.PtrOffset(4*4))
+	gl.EnableVertexAttribArray(2)
+}
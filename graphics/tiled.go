@@ -0,0 +1,636 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/QOthman/Pixu/graphics/atlas"
+)
+
+// TiledLayerKind is what a Tiled layer holds
+type TiledLayerKind int
+
+const (
+	TiledTileLayer TiledLayerKind = iota
+	TiledObjectLayer
+	TiledImageLayer
+)
+
+// TiledShape is the kind of a Tiled object
+type TiledShape int
+
+const (
+	TiledRectangle TiledShape = iota
+	TiledEllipse
+	TiledPoint
+	TiledPolygon
+	TiledPolyline
+	TiledTileObject // a tile placed freely, Tile holds its value
+	TiledText
+)
+
+// TiledMap is a map made in Tiled
+// Tile layers live in TileMap, all layers are listed in Layers in
+// drawing order, with groups flattened into them.
+type TiledMap struct {
+	TileMap         *TileMap
+	Layers          []*TiledLayer
+	Properties      TiledProperties
+	BackgroundColor Color
+
+	tileProps map[uint32]TiledProperties
+	tileClass map[uint32]string
+	images    []*Image // everything loaded, for Delete
+}
+
+// TiledLayer is one layer of a Tiled map
+type TiledLayer struct {
+	Kind       TiledLayerKind
+	ID         int
+	Name       string
+	Class      string
+	Visible    bool
+	Offset     Vector2
+	Parallax   Vector2
+	Tint       Color // includes the layer opacity
+	Properties TiledProperties
+
+	Tiles   *TileLayer     // tile layers
+	Objects []*TiledObject // object layers
+
+	Image            *Image // image layers
+	RepeatX, RepeatY bool
+}
+
+// TiledObject is an object from an object layer
+// X and Y are in map pixels. Tile objects sit on their bottom-left
+// corner, everything else on its top-left, and Rotation turns the
+// object clockwise around that point like in Tiled.
+type TiledObject struct {
+	ID            int
+	Name          string
+	Class         string
+	Shape         TiledShape
+	X, Y          float32
+	Width, Height float32
+	Rotation      float32   // degrees clockwise
+	Points        []Vector2 // polygon and polyline points, relative to X, Y
+	Tile          uint32    // tile objects, with flip flags
+	Text          string
+	Visible       bool
+	Properties    TiledProperties
+}
+
+// TiledProperties are custom properties
+// Values are string, int, float64, bool, Color or nested TiledProperties for classes.
+type TiledProperties map[string]any
+
+// String returns a string property, "" if missing
+func (p TiledProperties) String(name string) string {
+	s, _ := p[name].(string)
+	return s
+}
+
+// Int returns an int property, 0 if missing
+func (p TiledProperties) Int(name string) int {
+	switch v := p[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// Float returns a float property, 0 if missing
+func (p TiledProperties) Float(name string) float64 {
+	switch v := p[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// Bool returns a bool property, false if missing
+func (p TiledProperties) Bool(name string) bool {
+	b, _ := p[name].(bool)
+	return b
+}
+
+// LoadTiledMap loads a .tmx or .tmj map with its tilesets and images
+// Tilesets can be embedded or external .tsx/.tsj files. Only orthogonal
+// maps are supported. Infinite maps are cropped to the chunks they use.
+func LoadTiledMap(path string) (*TiledMap, error) {
+	doc, err := readTiledMap(path)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Orientation != "" && doc.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s: %s maps are not supported, only orthogonal", path, doc.Orientation)
+	}
+
+	l := &tiledLoader{
+		m: &TiledMap{
+			Properties: doc.Properties.convert(),
+			tileProps:  make(map[uint32]TiledProperties),
+			tileClass:  make(map[uint32]string),
+		},
+		images: make(map[string]*Image),
+	}
+	if doc.BackgroundColor != "" {
+		l.m.BackgroundColor = parseTiledColor(doc.BackgroundColor)
+	}
+
+	if err := l.load(path, doc); err != nil {
+		l.m.Delete()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l.m, nil
+}
+
+// State while a map is being built
+type tiledLoader struct {
+	m      *TiledMap
+	images map[string]*Image // loaded images by path
+	origin image.Point       // first tile of an infinite map
+}
+
+func (l *tiledLoader) load(path string, doc *tiledMapDoc) error {
+	dir := filepath.Dir(path)
+
+	// Infinite maps are as big as their chunks
+	width, height := doc.Width, doc.Height
+	if doc.Infinite {
+		bounds := image.Rectangle{}
+		for _, layer := range doc.Layers {
+			bounds = bounds.Union(layer.chunkBounds())
+		}
+		l.origin = bounds.Min
+		width, height = bounds.Dx(), bounds.Dy()
+	}
+	l.m.TileMap = &TileMap{
+		Width:          width,
+		Height:         height,
+		TileWidth:      doc.TileWidth,
+		TileHeight:     doc.TileHeight,
+		ParallaxOrigin: Vec2(doc.ParallaxOriginX, doc.ParallaxOriginY),
+	}
+
+	for _, ts := range doc.Tilesets {
+		if err := l.addTileset(dir, ts); err != nil {
+			return err
+		}
+	}
+	root := tiledInherited{parallax: Vec2(1, 1), tint: WHITE, visible: true}
+	for _, layer := range doc.Layers {
+		if err := l.addLayer(dir, layer, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load a tileset, reading it from its own file if it is external
+func (l *tiledLoader) addTileset(dir string, ts tiledTilesetDoc) error {
+	firstGID := ts.FirstGID
+	if ts.Source != "" {
+		source := filepath.Join(dir, ts.Source)
+		external, err := readTiledTileset(source)
+		if err != nil {
+			return err
+		}
+		ts, dir = *external, filepath.Dir(source)
+	}
+
+	var tileset *Tileset
+	if source := ts.image(); source != "" {
+		img, err := l.loadImage(filepath.Join(dir, source))
+		if err != nil {
+			return err
+		}
+		tileset = NewTileset(img, ts.TileWidth, ts.TileHeight, ts.Margin, ts.Spacing)
+		if ts.Columns > 0 {
+			tileset.Columns = ts.Columns
+		}
+		if ts.TileCount > 0 {
+			tileset.Count = ts.TileCount
+		}
+	} else {
+		var err error
+		if tileset, err = l.packCollection(dir, ts); err != nil {
+			return err
+		}
+	}
+	tileset.FirstID = firstGID
+	if ts.TileOffset != nil {
+		tileset.Offset = Vec2(ts.TileOffset.X, ts.TileOffset.Y)
+	}
+
+	for _, tile := range ts.Tiles {
+		gid := firstGID + uint32(tile.ID)
+		if len(tile.Properties) > 0 {
+			l.m.tileProps[gid] = tile.Properties.convert()
+		}
+		if class := tile.class(); class != "" {
+			l.m.tileClass[gid] = class
+		}
+		if len(tile.Animation) > 0 {
+			frames := make([]int, len(tile.Animation))
+			durations := make([]float64, len(tile.Animation))
+			for i, f := range tile.Animation {
+				frames[i], durations[i] = f.TileID, float64(f.Duration)/1000
+			}
+			tileset.SetAnimation(tile.ID, frames, durations)
+		}
+	}
+
+	// Tiled lists tilesets by first id already, keep it that way
+	tm := l.m.TileMap
+	i := len(tm.Tilesets)
+	for i > 0 && tm.Tilesets[i-1].FirstID > firstGID {
+		i--
+	}
+	tm.Tilesets = append(tm.Tilesets[:i], append([]*Tileset{tileset}, tm.Tilesets[i:]...)...)
+	return nil
+}
+
+// Pack an image collection tileset, each tile its own image, into one texture
+func (l *tiledLoader) packCollection(dir string, ts tiledTilesetDoc) (*Tileset, error) {
+	images := make(map[string]image.Image)
+	count := 0
+	for _, tile := range ts.Tiles {
+		source := tile.image()
+		if source == "" {
+			continue
+		}
+		img, err := decodeImageFile(filepath.Join(dir, source))
+		if err != nil {
+			return nil, err
+		}
+		images[fmt.Sprint(tile.ID)] = img
+		count = max(count, tile.ID+1)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("tileset %q has no image", ts.Name)
+	}
+	packed, err := atlas.Pack(images, atlas.Options{Extrude: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(packed.Pages) > 1 {
+		return nil, fmt.Errorf("tileset %q does not fit in one texture", ts.Name)
+	}
+
+	page := NewImageFromImage(packed.Pages[0])
+	l.m.images = append(l.m.images, page)
+	rects := make([]Rectangle, count)
+	for _, r := range packed.Regions {
+		var id int
+		fmt.Sscan(r.Name, &id)
+		rects[id] = Rect(float32(r.X), float32(r.Y), float32(r.Width), float32(r.Height))
+	}
+	return NewTilesetRects(page, rects), nil
+}
+
+// What a group passes on to the layers inside it
+type tiledInherited struct {
+	offset, parallax Vector2
+	tint             Color // opacity is in the alpha
+	visible          bool
+}
+
+// Add a layer, or the layers of a group
+func (l *tiledLoader) addLayer(dir string, doc tiledLayerDoc, parent tiledInherited) error {
+	doc.setDefaults()
+	tint := WHITE
+	if doc.TintColor != "" {
+		tint = parseTiledColor(doc.TintColor)
+	}
+	state := tiledInherited{
+		offset:   parent.offset.Add(Vec2(doc.OffsetX, doc.OffsetY)),
+		parallax: Vec2(parent.parallax.X**doc.ParallaxX, parent.parallax.Y**doc.ParallaxY),
+		tint: Color{
+			tint.R * parent.tint.R,
+			tint.G * parent.tint.G,
+			tint.B * parent.tint.B,
+			tint.A * parent.tint.A * *doc.Opacity,
+		},
+		visible: parent.visible && *doc.Visible,
+	}
+
+	if doc.kind() == "group" {
+		for _, child := range doc.Layers {
+			if err := l.addLayer(dir, child, state); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	layer := &TiledLayer{
+		ID:         doc.ID,
+		Name:       doc.Name,
+		Class:      doc.Class,
+		Visible:    state.visible,
+		Offset:     state.offset,
+		Parallax:   state.parallax,
+		Tint:       state.tint,
+		Properties: doc.Props.convert(),
+	}
+
+	switch doc.kind() {
+	case "tilelayer":
+		tiles, err := l.layerTiles(doc)
+		if err != nil {
+			return fmt.Errorf("layer %q: %w", doc.Name, err)
+		}
+		layer.Kind = TiledTileLayer
+		layer.Tiles = l.m.TileMap.AddLayer(doc.Name)
+		layer.Tiles.Tiles = tiles
+		layer.Tiles.Visible = layer.Visible
+		layer.Tiles.Parallax = layer.Parallax
+		layer.Tiles.Tint = layer.Tint
+		// Infinite maps start at their first chunk
+		layer.Tiles.Offset = layer.Offset.Add(Vec2(
+			float32(l.origin.X*l.m.TileMap.TileWidth),
+			float32(l.origin.Y*l.m.TileMap.TileHeight)))
+	case "objectgroup":
+		layer.Kind = TiledObjectLayer
+		for _, o := range doc.Objects {
+			layer.Objects = append(layer.Objects, o.convert())
+		}
+	case "imagelayer":
+		layer.Kind = TiledImageLayer
+		layer.RepeatX, layer.RepeatY = doc.RepeatX, doc.RepeatY
+		if source := doc.image(); source != "" {
+			img, err := l.loadImage(filepath.Join(dir, source))
+			if err != nil {
+				return err
+			}
+			layer.Image = img
+		}
+	default:
+		return nil // editor settings and the like
+	}
+	l.m.Layers = append(l.m.Layers, layer)
+	return nil
+}
+
+// Tile values of a layer, spread over the whole map for infinite ones
+func (l *tiledLoader) layerTiles(doc tiledLayerDoc) ([]uint32, error) {
+	tm := l.m.TileMap
+	tiles := make([]uint32, tm.Width*tm.Height)
+	place := func(x0, y0, w int, data []uint32) {
+		for i, tile := range data {
+			x, y := x0+i%w-l.origin.X, y0+i/w-l.origin.Y
+			if x >= 0 && y >= 0 && x < tm.Width && y < tm.Height {
+				tiles[y*tm.Width+x] = tile
+			}
+		}
+	}
+
+	encoding, compression := doc.encoding()
+	for _, c := range doc.chunks() {
+		data, err := c.tiles(encoding, compression)
+		if err != nil {
+			return nil, err
+		}
+		place(c.X, c.Y, max(c.Width, 1), data)
+	}
+	if len(doc.chunks()) == 0 {
+		data, err := doc.Data.tiles(encoding, compression)
+		if err != nil {
+			return nil, err
+		}
+		place(l.origin.X, l.origin.Y, max(doc.Width, 1), data)
+	}
+	return tiles, nil
+}
+
+// Load an image once, maps often use the same one for several tilesets
+func (l *tiledLoader) loadImage(path string) (*Image, error) {
+	path = filepath.Clean(path)
+	if img, ok := l.images[path]; ok {
+		return img, nil
+	}
+	img, err := LoadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	l.images[path] = img
+	l.m.images = append(l.m.images, img)
+	return img, nil
+}
+
+// Layer returns the first layer with a name, nil if there is none
+func (m *TiledMap) Layer(name string) *TiledLayer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// ObjectByID returns the object with an id, nil if there is none
+func (m *TiledMap) ObjectByID(id int) *TiledObject {
+	for _, l := range m.Layers {
+		for _, o := range l.Objects {
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+// ObjectsByClass returns every object of a class, from all object layers
+func (m *TiledMap) ObjectsByClass(class string) []*TiledObject {
+	var objects []*TiledObject
+	for _, l := range m.Layers {
+		for _, o := range l.Objects {
+			if o.Class == class {
+				objects = append(objects, o)
+			}
+		}
+	}
+	return objects
+}
+
+// TileProperties returns the custom properties of a tile value, nil if it has none
+func (m *TiledMap) TileProperties(tile uint32) TiledProperties {
+	return m.tileProps[tile&^tileFlags]
+}
+
+// TileClass returns the class of a tile value, "" if it has none
+func (m *TiledMap) TileClass(tile uint32) string {
+	return m.tileClass[tile&^tileFlags]
+}
+
+// TileAt returns the tile value of a layer under a point in map pixels
+func (m *TiledMap) TileAt(layerName string, x, y float32) uint32 {
+	layer := m.Layer(layerName)
+	if layer == nil || layer.Tiles == nil {
+		return 0
+	}
+	tx, ty := m.TileMap.WorldToTile(x-layer.Tiles.Offset.X, y-layer.Tiles.Offset.Y)
+	return layer.Tiles.Tile(tx, ty)
+}
+
+// Update advances animated tiles by the last frame's delta time
+func (m *TiledMap) Update() {
+	m.TileMap.Update()
+}
+
+// UpdateBy advances animated tiles by dt seconds
+func (m *TiledMap) UpdateBy(dt float64) {
+	m.TileMap.UpdateBy(dt)
+}
+
+// Draw draws the visible layers in order
+// Object layers draw their tile objects, shapes are left to the game.
+func (m *TiledMap) Draw() {
+	for i := range m.Layers {
+		m.DrawLayer(i)
+	}
+}
+
+// DrawLayer draws one layer, use it to put sprites between layers
+func (m *TiledMap) DrawLayer(index int) {
+	if index < 0 || index >= len(m.Layers) {
+		return
+	}
+	l := m.Layers[index]
+	if !l.Visible {
+		return
+	}
+	switch l.Kind {
+	case TiledTileLayer:
+		for i, tl := range m.TileMap.Layers {
+			if tl == l.Tiles {
+				m.TileMap.DrawLayer(i)
+			}
+		}
+	case TiledImageLayer:
+		m.drawImageLayer(l)
+	case TiledObjectLayer:
+		PushTransform()
+		Translate(m.TileMap.parallaxShift(l.Offset, l.Parallax))
+		for _, o := range l.Objects {
+			if o.Visible && o.Shape == TiledTileObject {
+				m.drawTileObject(o, l.Tint)
+			}
+		}
+		PopTransform()
+	}
+}
+
+// Draw an image layer, repeated over the view if it repeats
+func (m *TiledMap) drawImageLayer(l *TiledLayer) {
+	img := l.Image
+	if img == nil || img.Width == 0 || img.Height == 0 {
+		return
+	}
+	x, y := m.TileMap.parallaxShift(l.Offset, l.Parallax)
+	w, h := float32(img.Width), float32(img.Height)
+	x0, y0, x1, y1 := x, y, x, y
+	if l.RepeatX || l.RepeatY {
+		minX, minY, maxX, maxY := surfaceBounds(viewMatrix.Mul(modelMatrix).Invert())
+		if l.RepeatX {
+			x0 = x + float32(floorDiv(minX-x, w))*w
+			x1 = maxX
+		}
+		if l.RepeatY {
+			y0 = y + float32(floorDiv(minY-y, h))*h
+			y1 = maxY
+		}
+	}
+	for ty := y0; ty <= y1; ty += h {
+		for tx := x0; tx <= x1; tx += w {
+			DrawImageEx(img, DrawOptions{X: tx, Y: ty, Width: w, Height: h, Tint: l.Tint})
+		}
+	}
+}
+
+// Draw a tile object, stretched to its size and turned around its bottom-left corner
+func (m *TiledMap) drawTileObject(o *TiledObject, tint Color) {
+	tileset, index := m.TileMap.tilesetFor(o.Tile)
+	if tileset == nil || tileset.Image == nil {
+		return
+	}
+	if anim := tileset.animations[index]; anim != nil {
+		index = anim.frameAt(m.TileMap.Time)
+	}
+	src := tileset.TileRect(index)
+	w, h := o.Width, o.Height
+	if w == 0 || h == 0 {
+		w, h = src.Width, src.Height
+	}
+
+	PushTransform()
+	Translate(o.X, o.Y)
+	Rotate(-o.Rotation)
+	vertices, indices := appendTileQuad(nil, nil, tileset.Image, src, o.Tile, Rect(0, -h, w, h), tint)
+	for i := 0; i < len(vertices); i += 8 {
+		vertices[i], vertices[i+1] = transformPoint(vertices[i], vertices[i+1])
+	}
+	PopTransform()
+	drawTexturedQuad(tileset.Image, vertices, indices)
+}
+
+// Delete frees every image the map loaded
+func (m *TiledMap) Delete() {
+	for _, img := range m.images {
+		img.Delete()
+	}
+	m.images = nil
+}
+
+// Bounds returns the object's box in map pixels, ignoring rotation
+// Tile objects grow up from their position, other objects down.
+func (o *TiledObject) Bounds() Rectangle {
+	if o.Shape == TiledTileObject {
+		return Rect(o.X, o.Y-o.Height, o.Width, o.Height)
+	}
+	if o.Shape == TiledPolygon || o.Shape == TiledPolyline {
+		if len(o.Points) == 0 {
+			return Rect(o.X, o.Y, 0, 0)
+		}
+		minX, minY, maxX, maxY := o.Points[0].X, o.Points[0].Y, o.Points[0].X, o.Points[0].Y
+		for _, p := range o.Points[1:] {
+			minX, minY = min(minX, p.X), min(minY, p.Y)
+			maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+		}
+		return Rect(o.X+minX, o.Y+minY, maxX-minX, maxY-minY)
+	}
+	return Rect(o.X, o.Y, o.Width, o.Height)
+}
+
+// WorldPoints returns the polygon or polyline points in map pixels, rotation applied
+func (o *TiledObject) WorldPoints() []Vector2 {
+	turn := MatrixTranslate(o.X, o.Y).Mul(MatrixRotate(-o.Rotation))
+	points := make([]Vector2, len(o.Points))
+	for i, p := range o.Points {
+		points[i].X, points[i].Y = turn.Apply(p.X, p.Y)
+	}
+	return points
+}
+
+// Tiled colors are #RRGGBB or #AARRGGBB
+func parseTiledColor(s string) Color {
+	s = strings.TrimPrefix(s, "#")
+	var a, r, g, b uint8 = 255, 0, 0, 0
+	switch len(s) {
+	case 6:
+		fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b)
+	case 8:
+		fmt.Sscanf(s, "%02x%02x%02x%02x", &a, &r, &g, &b)
+	default:
+		return WHITE
+	}
+	return Color{float32(r) / 255, float32(g) / 255, float32(b) / 255, float32(a) / 255}
+}
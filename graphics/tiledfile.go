@@ -0,0 +1,505 @@
+package graphics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tiled files as written in XML (.tmx, .tsx) and JSON (.tmj, .tsj)
+// The two formats mostly use the same names, so one set of structs
+// reads both. Fields tagged for only one format cover where they differ.
+
+type tiledMapDoc struct {
+	Orientation     string            `xml:"orientation,attr" json:"orientation"`
+	Width           int               `xml:"width,attr" json:"width"`
+	Height          int               `xml:"height,attr" json:"height"`
+	TileWidth       int               `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight      int               `xml:"tileheight,attr" json:"tileheight"`
+	Infinite        bool              `xml:"infinite,attr" json:"infinite"`
+	BackgroundColor string            `xml:"backgroundcolor,attr" json:"backgroundcolor"`
+	ParallaxOriginX float32           `xml:"parallaxoriginx,attr" json:"parallaxoriginx"`
+	ParallaxOriginY float32           `xml:"parallaxoriginy,attr" json:"parallaxoriginy"`
+	Properties      tiledPropertyDocs `xml:"properties>property" json:"properties"`
+	Tilesets        []tiledTilesetDoc `xml:"tileset" json:"tilesets"`
+	Layers          []tiledLayerDoc   `xml:",any" json:"layers"` // layer, objectgroup, imagelayer and group in order
+}
+
+type tiledTilesetDoc struct {
+	FirstGID   uint32            `xml:"firstgid,attr" json:"firstgid"`
+	Source     string            `xml:"source,attr" json:"source"`
+	Name       string            `xml:"name,attr" json:"name"`
+	TileWidth  int               `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight int               `xml:"tileheight,attr" json:"tileheight"`
+	Spacing    int               `xml:"spacing,attr" json:"spacing"`
+	Margin     int               `xml:"margin,attr" json:"margin"`
+	TileCount  int               `xml:"tilecount,attr" json:"tilecount"`
+	Columns    int               `xml:"columns,attr" json:"columns"`
+	ImageXML   tiledImageDoc     `xml:"image" json:"-"`
+	Image      string            `xml:"-" json:"image"`
+	TileOffset *tiledPointDoc    `xml:"tileoffset" json:"tileoffset"`
+	Tiles      []tiledTileDoc    `xml:"tile" json:"tiles"`
+	Properties tiledPropertyDocs `xml:"properties>property" json:"properties"`
+}
+
+type tiledTileDoc struct {
+	ID         int               `xml:"id,attr" json:"id"`
+	Type       string            `xml:"type,attr" json:"type"` // called class since Tiled 1.9
+	Class      string            `xml:"class,attr" json:"class"`
+	ImageXML   tiledImageDoc     `xml:"image" json:"-"`
+	Image      string            `xml:"-" json:"image"`
+	Animation  []tiledFrameDoc   `xml:"animation>frame" json:"animation"`
+	Properties tiledPropertyDocs `xml:"properties>property" json:"properties"`
+}
+
+type tiledFrameDoc struct {
+	TileID   int `xml:"tileid,attr" json:"tileid"`
+	Duration int `xml:"duration,attr" json:"duration"` // milliseconds
+}
+
+type tiledImageDoc struct {
+	Source string `xml:"source,attr"`
+}
+
+type tiledPointDoc struct {
+	X float32 `xml:"x,attr" json:"x"`
+	Y float32 `xml:"y,attr" json:"y"`
+}
+
+type tiledLayerDoc struct {
+	XMLName   xml.Name          `json:"-"`
+	Type      string            `xml:"-" json:"type"`
+	ID        int               `xml:"id,attr" json:"id"`
+	Name      string            `xml:"name,attr" json:"name"`
+	Class     string            `xml:"class,attr" json:"class"`
+	Visible   *bool             `xml:"visible,attr" json:"visible"`
+	Opacity   *float32          `xml:"opacity,attr" json:"opacity"`
+	OffsetX   float32           `xml:"offsetx,attr" json:"offsetx"`
+	OffsetY   float32           `xml:"offsety,attr" json:"offsety"`
+	ParallaxX *float32          `xml:"parallaxx,attr" json:"parallaxx"`
+	ParallaxY *float32          `xml:"parallaxy,attr" json:"parallaxy"`
+	TintColor string            `xml:"tintcolor,attr" json:"tintcolor"`
+	Width     int               `xml:"width,attr" json:"width"`
+	Height    int               `xml:"height,attr" json:"height"`
+	Props     tiledPropertyDocs `xml:"properties>property" json:"properties"`
+
+	// Tile layers, JSON puts the encoding on the layer and chunks next to data
+	Data        tiledDataDoc    `xml:"data" json:"data"`
+	Encoding    string          `xml:"-" json:"encoding"`
+	Compression string          `xml:"-" json:"compression"`
+	Chunks      []tiledChunkDoc `xml:"-" json:"chunks"`
+
+	Objects []tiledObjectDoc `xml:"object" json:"objects"`
+
+	ImageXML tiledImageDoc `xml:"image" json:"-"`
+	Image    string        `xml:"-" json:"image"`
+	RepeatX  bool          `xml:"repeatx,attr" json:"repeatx"`
+	RepeatY  bool          `xml:"repeaty,attr" json:"repeaty"`
+
+	Layers []tiledLayerDoc `xml:",any" json:"layers"` // groups
+}
+
+// Tile data is CSV or base64 text, <tile> elements or <chunk>s in XML,
+// and an array of numbers or a base64 string in JSON
+type tiledDataDoc struct {
+	Encoding    string          `xml:"encoding,attr"`
+	Compression string          `xml:"compression,attr"`
+	Text        string          `xml:",chardata"`
+	Tiles       []tiledGIDDoc   `xml:"tile"`
+	Chunks      []tiledChunkDoc `xml:"chunk"`
+	values      []uint32
+}
+
+type tiledGIDDoc struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type tiledChunkDoc struct {
+	X      int           `xml:"x,attr" json:"x"`
+	Y      int           `xml:"y,attr" json:"y"`
+	Width  int           `xml:"width,attr" json:"width"`
+	Height int           `xml:"height,attr" json:"height"`
+	Text   string        `xml:",chardata" json:"-"`
+	Tiles  []tiledGIDDoc `xml:"tile" json:"-"`
+	Data   tiledDataDoc  `xml:"-" json:"data"`
+}
+
+type tiledObjectDoc struct {
+	ID       int               `xml:"id,attr" json:"id"`
+	Name     string            `xml:"name,attr" json:"name"`
+	Type     string            `xml:"type,attr" json:"type"` // called class since Tiled 1.9
+	Class    string            `xml:"class,attr" json:"class"`
+	X        float32           `xml:"x,attr" json:"x"`
+	Y        float32           `xml:"y,attr" json:"y"`
+	Width    float32           `xml:"width,attr" json:"width"`
+	Height   float32           `xml:"height,attr" json:"height"`
+	Rotation float32           `xml:"rotation,attr" json:"rotation"`
+	GID      uint32            `xml:"gid,attr" json:"gid"`
+	Visible  *bool             `xml:"visible,attr" json:"visible"`
+	Props    tiledPropertyDocs `xml:"properties>property" json:"properties"`
+
+	EllipseXML  *struct{}              `xml:"ellipse" json:"-"`
+	Ellipse     bool                   `xml:"-" json:"ellipse"`
+	PointXML    *struct{}              `xml:"point" json:"-"`
+	Point       bool                   `xml:"-" json:"point"`
+	PolygonXML  *tiledPointsDoc        `xml:"polygon" json:"-"`
+	Polygon     []Vector2              `xml:"-" json:"polygon"`
+	PolylineXML *tiledPointsDoc        `xml:"polyline" json:"-"`
+	Polyline    []Vector2              `xml:"-" json:"polyline"`
+	TextXML     *tiledTextDoc          `xml:"text" json:"-"`
+	Text        *struct{ Text string } `xml:"-" json:"text"`
+}
+
+type tiledPointsDoc struct {
+	Points string `xml:"points,attr"` // "x,y x,y ..."
+}
+
+type tiledTextDoc struct {
+	Text string `xml:",chardata"`
+}
+
+// Custom properties, the value is an attribute or text in XML and typed in JSON
+type tiledPropertyDoc struct {
+	Name      string            `xml:"name,attr" json:"name"`
+	Type      string            `xml:"type,attr" json:"type"`
+	ValueAttr *string           `xml:"value,attr" json:"-"`
+	Text      string            `xml:",chardata" json:"-"`
+	Value     any               `xml:"-" json:"value"`
+	Members   tiledPropertyDocs `xml:"properties>property" json:"-"` // class values in XML
+}
+
+type tiledPropertyDocs []tiledPropertyDoc
+
+// Read a .tmx or .tmj file
+func readTiledMap(path string) (*tiledMapDoc, error) {
+	var doc tiledMapDoc
+	if err := readTiledFile(path, ".tmx", &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Read a .tsx or .tsj file
+func readTiledTileset(path string) (*tiledTilesetDoc, error) {
+	var doc tiledTilesetDoc
+	if err := readTiledFile(path, ".tsx", &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func readTiledFile(path, xmlExt string, doc any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), xmlExt) {
+		err = xml.Unmarshal(data, doc)
+	} else {
+		err = json.Unmarshal(data, doc)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (d *tiledDataDoc) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &d.Text)
+	}
+	return json.Unmarshal(data, &d.values)
+}
+
+// Decode tile values, encoding and compression come from the layer in JSON
+func (d *tiledDataDoc) tiles(encoding, compression string) ([]uint32, error) {
+	if d.values != nil {
+		return d.values, nil
+	}
+	if len(d.Tiles) > 0 {
+		tiles := make([]uint32, len(d.Tiles))
+		for i, t := range d.Tiles {
+			tiles[i] = t.GID
+		}
+		return tiles, nil
+	}
+	text := strings.TrimSpace(d.Text)
+	if text == "" {
+		return nil, nil
+	}
+
+	switch encoding {
+	case "csv":
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+		})
+		tiles := make([]uint32, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("bad tile %q", f)
+			}
+			tiles[i] = uint32(v)
+		}
+		return tiles, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, err
+		}
+		if raw, err = decompressTiled(raw, compression); err != nil {
+			return nil, err
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("tile data is %d bytes, not a multiple of 4", len(raw))
+		}
+		tiles := make([]uint32, len(raw)/4)
+		for i := range tiles {
+			tiles[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return tiles, nil
+	}
+	return nil, fmt.Errorf("unknown tile data encoding %q", encoding)
+}
+
+func decompressTiled(raw []byte, compression string) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch compression {
+	case "":
+		return raw, nil
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("%s compressed tile data is not supported, use zlib, gzip or none", compression)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (c *tiledChunkDoc) tiles(encoding, compression string) ([]uint32, error) {
+	if c.Data.values == nil && c.Data.Text == "" {
+		// XML keeps the data right in the chunk
+		c.Data.Text, c.Data.Tiles = c.Text, c.Tiles
+	}
+	return c.Data.tiles(encoding, compression)
+}
+
+// Layer type under its JSON name
+func (d *tiledLayerDoc) kind() string {
+	switch d.XMLName.Local {
+	case "":
+		return d.Type
+	case "layer":
+		return "tilelayer"
+	}
+	return d.XMLName.Local
+}
+
+func (d *tiledLayerDoc) setDefaults() {
+	one, yes := float32(1), true
+	if d.Visible == nil {
+		d.Visible = &yes
+	}
+	if d.Opacity == nil {
+		d.Opacity = &one
+	}
+	if d.ParallaxX == nil {
+		px := one
+		d.ParallaxX = &px
+	}
+	if d.ParallaxY == nil {
+		py := one
+		d.ParallaxY = &py
+	}
+}
+
+func (d *tiledLayerDoc) encoding() (string, string) {
+	if d.Data.Encoding != "" {
+		return d.Data.Encoding, d.Data.Compression
+	}
+	if d.Encoding == "" && d.Data.Text != "" {
+		return "csv", "" // JSON without an encoding only has arrays, XML defaults to <tile>s
+	}
+	return d.Encoding, d.Compression
+}
+
+func (d *tiledLayerDoc) chunks() []tiledChunkDoc {
+	if len(d.Data.Chunks) > 0 {
+		return d.Data.Chunks
+	}
+	return d.Chunks
+}
+
+// Cells covered by an infinite map layer's chunks, groups included
+func (d *tiledLayerDoc) chunkBounds() image.Rectangle {
+	bounds := image.Rectangle{}
+	for _, c := range d.chunks() {
+		bounds = bounds.Union(image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height))
+	}
+	for _, child := range d.Layers {
+		bounds = bounds.Union(child.chunkBounds())
+	}
+	return bounds
+}
+
+func (d *tiledLayerDoc) image() string {
+	if d.ImageXML.Source != "" {
+		return d.ImageXML.Source
+	}
+	return d.Image
+}
+
+func (d *tiledTilesetDoc) image() string {
+	if d.ImageXML.Source != "" {
+		return d.ImageXML.Source
+	}
+	return d.Image
+}
+
+func (d *tiledTileDoc) image() string {
+	if d.ImageXML.Source != "" {
+		return d.ImageXML.Source
+	}
+	return d.Image
+}
+
+func (d *tiledTileDoc) class() string {
+	if d.Class != "" {
+		return d.Class
+	}
+	return d.Type
+}
+
+func (d *tiledObjectDoc) convert() *TiledObject {
+	o := &TiledObject{
+		ID:         d.ID,
+		Name:       d.Name,
+		Class:      d.Class,
+		X:          d.X,
+		Y:          d.Y,
+		Width:      d.Width,
+		Height:     d.Height,
+		Rotation:   d.Rotation,
+		Visible:    d.Visible == nil || *d.Visible,
+		Properties: d.Props.convert(),
+	}
+	if o.Class == "" {
+		o.Class = d.Type
+	}
+	switch {
+	case d.GID != 0:
+		o.Shape, o.Tile = TiledTileObject, d.GID
+	case d.EllipseXML != nil || d.Ellipse:
+		o.Shape = TiledEllipse
+	case d.PointXML != nil || d.Point:
+		o.Shape = TiledPoint
+	case d.PolygonXML != nil:
+		o.Shape, o.Points = TiledPolygon, parseTiledPoints(d.PolygonXML.Points)
+	case d.Polygon != nil:
+		o.Shape, o.Points = TiledPolygon, d.Polygon
+	case d.PolylineXML != nil:
+		o.Shape, o.Points = TiledPolyline, parseTiledPoints(d.PolylineXML.Points)
+	case d.Polyline != nil:
+		o.Shape, o.Points = TiledPolyline, d.Polyline
+	case d.TextXML != nil:
+		o.Shape, o.Text = TiledText, d.TextXML.Text
+	case d.Text != nil:
+		o.Shape, o.Text = TiledText, d.Text.Text
+	}
+	return o
+}
+
+func parseTiledPoints(s string) []Vector2 {
+	var points []Vector2
+	for _, pair := range strings.Fields(s) {
+		var p Vector2
+		if _, err := fmt.Sscanf(pair, "%g,%g", &p.X, &p.Y); err == nil {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+func (docs tiledPropertyDocs) convert() TiledProperties {
+	if len(docs) == 0 {
+		return nil
+	}
+	props := make(TiledProperties, len(docs))
+	for _, p := range docs {
+		props[p.Name] = p.value()
+	}
+	return props
+}
+
+func (p *tiledPropertyDoc) value() any {
+	if p.Value != nil {
+		return tiledJSONValue(p.Type, p.Value)
+	}
+	if p.Type == "class" {
+		return p.Members.convert()
+	}
+	raw := p.Text
+	if p.ValueAttr != nil {
+		raw = *p.ValueAttr
+	}
+	switch p.Type {
+	case "int", "object":
+		v, _ := strconv.Atoi(raw)
+		return v
+	case "float":
+		v, _ := strconv.ParseFloat(raw, 64)
+		return v
+	case "bool":
+		v, _ := strconv.ParseBool(raw)
+		return v
+	case "color":
+		if raw == "" {
+			return Color{}
+		}
+		return parseTiledColor(raw)
+	}
+	return raw
+}
+
+// JSON numbers come as float64 and class values as objects
+func tiledJSONValue(kind string, v any) any {
+	switch v := v.(type) {
+	case float64:
+		if kind == "int" || kind == "object" {
+			return int(v)
+		}
+		return v
+	case string:
+		if kind == "color" {
+			if v == "" {
+				return Color{}
+			}
+			return parseTiledColor(v)
+		}
+		return v
+	case map[string]any:
+		props := make(TiledProperties, len(v))
+		for name, member := range v {
+			props[name] = tiledJSONValue("", member)
+		}
+		return props
+	}
+	return v
+}
@@ -0,0 +1,480 @@
+package graphics
+
+// Tile flags, stored in the top bits of a tile value the way Tiled does
+// The diagonal flip swaps x and y first, then the other two mirror.
+const (
+	TileFlipX        uint32 = 1 << 31 // mirrored left to right
+	TileFlipY        uint32 = 1 << 30 // mirrored top to bottom
+	TileFlipDiagonal uint32 = 1 << 29 // x and y swapped
+
+	TileRotate90  = TileFlipDiagonal | TileFlipX // turned clockwise
+	TileRotate180 = TileFlipX | TileFlipY
+	TileRotate270 = TileFlipDiagonal | TileFlipY
+
+	tileFlags = TileFlipX | TileFlipY | TileFlipDiagonal | 1<<28 // 1<<28 is Tiled's hexagon rotation
+)
+
+// Tiles per chunk side, chunks are built and culled as a whole
+const tileChunkSize = 32
+
+// Tileset is an image cut into equal tiles
+type Tileset struct {
+	Image                 *Image
+	TileWidth, TileHeight int
+	Margin, Spacing       int
+	Columns, Count        int
+	FirstID               uint32  // tile value of the first tile, set by TileMap.AddTileset
+	Offset                Vector2 // shift applied when drawing its tiles
+
+	animations map[int]*TileAnimation
+	rects      []Rectangle // tiles of differing sizes, instead of the grid
+}
+
+// TileAnimation cycles a tile through other tiles of its tileset
+type TileAnimation struct {
+	Frames    []int     // tile indices in the tileset
+	Durations []float64 // seconds per frame
+	duration  float64
+}
+
+// NewTileset cuts an image into tiles, left to right then top to bottom
+func NewTileset(img *Image, tileWidth, tileHeight, margin, spacing int) *Tileset {
+	t := &Tileset{Image: img, TileWidth: tileWidth, TileHeight: tileHeight, Margin: margin, Spacing: spacing, FirstID: 1}
+	if tileWidth > 0 && tileHeight > 0 {
+		t.Columns = max((int(img.Width)-2*margin+spacing)/(tileWidth+spacing), 0)
+		rows := max((int(img.Height)-2*margin+spacing)/(tileHeight+spacing), 0)
+		t.Count = t.Columns * rows
+	}
+	return t
+}
+
+// SetAnimation makes a tile play through frames, each shown for its duration in seconds
+// The last duration is used for the frames after it.
+func (t *Tileset) SetAnimation(tile int, frames []int, durations []float64) {
+	if t.animations == nil {
+		t.animations = make(map[int]*TileAnimation)
+	}
+	if len(frames) == 0 {
+		delete(t.animations, tile)
+		return
+	}
+	anim := &TileAnimation{Frames: frames}
+	for i := range frames {
+		d := 0.1
+		if len(durations) > 0 {
+			d = max(durations[min(i, len(durations)-1)], 0.001)
+		}
+		anim.Durations = append(anim.Durations, d)
+		anim.duration += d
+	}
+	t.animations[tile] = anim
+}
+
+// Animation returns a tile's animation, nil if it doesn't have one
+func (t *Tileset) Animation(tile int) *TileAnimation {
+	return t.animations[tile]
+}
+
+// NewTilesetRects creates a tileset whose tiles are arbitrary areas of the image
+// Tiles can have different sizes, TileWidth and TileHeight are the largest.
+func NewTilesetRects(img *Image, rects []Rectangle) *Tileset {
+	t := &Tileset{Image: img, FirstID: 1, Count: len(rects), rects: rects}
+	for _, r := range rects {
+		t.TileWidth = max(t.TileWidth, int(r.Width))
+		t.TileHeight = max(t.TileHeight, int(r.Height))
+	}
+	return t
+}
+
+// TileRect returns where a tile is in the image, from the top-left
+func (t *Tileset) TileRect(tile int) Rectangle {
+	if t.rects != nil {
+		if tile < 0 || tile >= len(t.rects) {
+			return Rectangle{}
+		}
+		return t.rects[tile]
+	}
+	if t.Columns == 0 {
+		return Rectangle{}
+	}
+	col, row := tile%t.Columns, tile/t.Columns
+	return Rect(
+		float32(t.Margin+col*(t.TileWidth+t.Spacing)),
+		float32(t.Margin+row*(t.TileHeight+t.Spacing)),
+		float32(t.TileWidth), float32(t.TileHeight))
+}
+
+// Tile shown by an animation at time t
+func (a *TileAnimation) frameAt(t float64) int {
+	t -= float64(int(t/a.duration)) * a.duration
+	for i, d := range a.Durations {
+		if t < d {
+			return a.Frames[i]
+		}
+		t -= d
+	}
+	return a.Frames[len(a.Frames)-1]
+}
+
+// TileLayer is one grid of tile values
+// 0 is an empty cell, otherwise the value is a tileset's FirstID plus
+// the tile index, with the TileFlip flags on top.
+type TileLayer struct {
+	Name     string
+	Tiles    []uint32 // Width*Height values, row by row
+	Visible  bool
+	Parallax Vector2 // how much it scrolls with the camera, 1 is normal, 0 stays put
+	Offset   Vector2 // shift in pixels
+	Tint     Color
+
+	tilemap *TileMap
+	chunks  []*tileChunk
+}
+
+// Static geometry for a square of tiles, one part per tileset used
+type tileChunk struct {
+	dirty    bool
+	tint     Color
+	parts    []tileChunkPart
+	animated []int           // cells with animated tiles, drawn every frame
+	frames   []tileChunkPart // animated tiles at the current frame, reused every draw
+}
+
+type tileChunkPart struct {
+	tileset  *Tileset
+	vertices []float32
+	indices  []uint32
+}
+
+// TileMap draws tile layers over a grid
+// Each layer is cut into chunks whose vertices are built once and
+// reused until a tile changes. They are kept on the CPU and go through
+// the batch like any other draw every frame, only the building is saved.
+// Chunks outside the view are skipped.
+type TileMap struct {
+	Width, Height         int // size in tiles
+	TileWidth, TileHeight int // grid cell size in pixels
+	Tilesets              []*Tileset
+	Layers                []*TileLayer
+	ParallaxOrigin        Vector2 // point where parallax layers line up with the others
+	Time                  float64 // clock for animated tiles, advanced by Update
+}
+
+// NewTileMap creates an empty map using one tileset
+func NewTileMap(width, height, tileWidth, tileHeight int, tileset *Tileset) *TileMap {
+	m := &TileMap{Width: width, Height: height, TileWidth: tileWidth, TileHeight: tileHeight}
+	if tileset != nil {
+		m.AddTileset(tileset)
+	}
+	return m
+}
+
+// AddTileset adds a tileset whose tiles come after the ones already added
+// It sets the tileset's FirstID and returns it.
+func (m *TileMap) AddTileset(tileset *Tileset) uint32 {
+	tileset.FirstID = 1
+	if n := len(m.Tilesets); n > 0 {
+		last := m.Tilesets[n-1]
+		tileset.FirstID = last.FirstID + uint32(max(last.Count, 1))
+	}
+	m.Tilesets = append(m.Tilesets, tileset)
+	m.Invalidate()
+	return tileset.FirstID
+}
+
+// AddLayer adds an empty layer on top of the others
+func (m *TileMap) AddLayer(name string) *TileLayer {
+	l := &TileLayer{
+		Name:     name,
+		Tiles:    make([]uint32, m.Width*m.Height),
+		Visible:  true,
+		Parallax: Vec2(1, 1),
+		Tint:     WHITE,
+		tilemap:  m,
+	}
+	m.Layers = append(m.Layers, l)
+	return l
+}
+
+// Layer returns the first layer with a name, nil if there is none
+func (m *TileMap) Layer(name string) *TileLayer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Invalidate rebuilds every chunk on the next draw
+// Call it after changing Tiles or a tileset directly.
+func (m *TileMap) Invalidate() {
+	for _, l := range m.Layers {
+		l.chunks = nil
+	}
+}
+
+// Tile returns the value at a cell, 0 outside the map
+func (l *TileLayer) Tile(x, y int) uint32 {
+	m := l.tilemap
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return 0
+	}
+	return l.Tiles[y*m.Width+x]
+}
+
+// SetTile changes a cell, only its chunk is rebuilt
+func (l *TileLayer) SetTile(x, y int, tile uint32) {
+	m := l.tilemap
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return
+	}
+	l.Tiles[y*m.Width+x] = tile
+	if l.chunks != nil {
+		l.chunks[(y/tileChunkSize)*l.chunksX()+x/tileChunkSize].dirty = true
+	}
+}
+
+// WorldToTile returns the cell under a point in map pixels
+func (m *TileMap) WorldToTile(x, y float32) (int, int) {
+	return floorDiv(x, float32(m.TileWidth)), floorDiv(y, float32(m.TileHeight))
+}
+
+func floorDiv(v, size float32) int {
+	i := int(v / size)
+	if v < 0 && float32(i)*size != v {
+		i--
+	}
+	return i
+}
+
+// Tileset a tile value belongs to and the tile index in it
+func (m *TileMap) tilesetFor(tile uint32) (*Tileset, int) {
+	id := tile &^ tileFlags
+	if id == 0 {
+		return nil, 0
+	}
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		if t := m.Tilesets[i]; id >= t.FirstID {
+			return t, int(id - t.FirstID)
+		}
+	}
+	return nil, 0
+}
+
+// Update advances animated tiles by the last frame's delta time
+func (m *TileMap) Update() {
+	m.UpdateBy(GetDeltaTime())
+}
+
+// UpdateBy advances animated tiles by dt seconds
+func (m *TileMap) UpdateBy(dt float64) {
+	m.Time += dt
+}
+
+// Draw draws the visible layers in order
+func (m *TileMap) Draw() {
+	for i := range m.Layers {
+		m.DrawLayer(i)
+	}
+}
+
+// DrawLayer draws one layer, use it to put sprites between layers
+func (m *TileMap) DrawLayer(index int) {
+	if index < 0 || index >= len(m.Layers) || m.Width <= 0 || m.Height <= 0 {
+		return
+	}
+	l := m.Layers[index]
+	if !l.Visible || l.Tint.A <= 0 {
+		return
+	}
+	if l.chunks == nil {
+		l.chunks = make([]*tileChunk, l.chunksX()*l.chunksY())
+		for i := range l.chunks {
+			l.chunks[i] = &tileChunk{dirty: true}
+		}
+	}
+
+	// Vertices are in layer pixels, the shift and current transform go in the draw state
+	local := modelMatrix.Mul(MatrixTranslate(m.parallaxShift(l.Offset, l.Parallax)))
+	view := viewMatrix.Mul(local).Invert()
+	minX, minY, maxX, maxY := surfaceBounds(view)
+
+	cw, ch := float32(tileChunkSize*m.TileWidth), float32(tileChunkSize*m.TileHeight)
+	// Tiles taller or wider than the grid reach into the chunks above and left
+	reachX, reachY := float32(0), float32(0)
+	for _, t := range m.Tilesets {
+		reachX = max(reachX, float32(t.TileWidth-m.TileWidth))
+		reachY = max(reachY, float32(t.TileHeight-m.TileHeight))
+	}
+	x0, y0 := max(floorDiv(minX-reachX, cw), 0), max(floorDiv(minY, ch), 0)
+	x1, y1 := min(floorDiv(maxX, cw), l.chunksX()-1), min(floorDiv(maxY+reachY, ch), l.chunksY()-1)
+
+	for cy := y0; cy <= y1; cy++ {
+		for cx := x0; cx <= x1; cx++ {
+			chunk := l.chunks[cy*l.chunksX()+cx]
+			if chunk.dirty || chunk.tint != l.Tint {
+				l.buildChunk(chunk, cx, cy)
+			}
+			for _, part := range chunk.parts {
+				l.drawPart(part.tileset, local, part.vertices, part.indices)
+			}
+			if len(chunk.animated) > 0 {
+				l.drawAnimated(chunk, local)
+			}
+		}
+	}
+}
+
+// Where a layer moves to for its offset and parallax
+func (m *TileMap) parallaxShift(offset, parallax Vector2) (float32, float32) {
+	x, y := offset.X, offset.Y
+	if parallax != Vec2(1, 1) {
+		// How far the view center is from the origin, in map pixels
+		center := viewMatrix.Mul(modelMatrix).Invert()
+		cx, cy := center.Apply(float32(surfaceWidth)/2, float32(surfaceHeight)/2)
+		x += (cx - m.ParallaxOrigin.X) * (1 - parallax.X)
+		y += (cy - m.ParallaxOrigin.Y) * (1 - parallax.Y)
+	}
+	return x, y
+}
+
+// Box around the surface corners mapped through a screen to world matrix
+func surfaceBounds(inverse Matrix) (minX, minY, maxX, maxY float32) {
+	w, h := float32(surfaceWidth), float32(surfaceHeight)
+	corners := [4][2]float32{{0, 0}, {w, 0}, {w, h}, {0, h}}
+	for i, c := range corners {
+		x, y := inverse.Apply(c[0], c[1])
+		if i == 0 {
+			minX, minY, maxX, maxY = x, y, x, y
+			continue
+		}
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+	return minX, minY, maxX, maxY
+}
+
+func (l *TileLayer) chunksX() int {
+	return (l.tilemap.Width + tileChunkSize - 1) / tileChunkSize
+}
+
+func (l *TileLayer) chunksY() int {
+	return (l.tilemap.Height + tileChunkSize - 1) / tileChunkSize
+}
+
+// Rebuild a chunk's static vertices
+func (l *TileLayer) buildChunk(chunk *tileChunk, cx, cy int) {
+	m := l.tilemap
+	for i := range chunk.parts {
+		chunk.parts[i].vertices = chunk.parts[i].vertices[:0]
+		chunk.parts[i].indices = chunk.parts[i].indices[:0]
+	}
+	chunk.animated = chunk.animated[:0]
+
+	for y := cy * tileChunkSize; y < min((cy+1)*tileChunkSize, m.Height); y++ {
+		for x := cx * tileChunkSize; x < min((cx+1)*tileChunkSize, m.Width); x++ {
+			tile := l.Tiles[y*m.Width+x]
+			tileset, index := m.tilesetFor(tile)
+			if tileset == nil || tileset.Image == nil {
+				continue
+			}
+			if tileset.animations[index] != nil {
+				chunk.animated = append(chunk.animated, y*m.Width+x)
+				continue
+			}
+			part := chunkPart(&chunk.parts, tileset)
+			part.vertices, part.indices = m.appendTile(part.vertices, part.indices, tileset, index, tile, x, y, l.Tint)
+		}
+	}
+
+	// Drop parts of tilesets that are no longer used
+	parts := chunk.parts[:0]
+	for _, p := range chunk.parts {
+		if len(p.indices) > 0 {
+			parts = append(parts, p)
+		}
+	}
+	chunk.parts = parts
+	chunk.dirty, chunk.tint = false, l.Tint
+}
+
+func chunkPart(parts *[]tileChunkPart, tileset *Tileset) *tileChunkPart {
+	for i := range *parts {
+		if (*parts)[i].tileset == tileset {
+			return &(*parts)[i]
+		}
+	}
+	*parts = append(*parts, tileChunkPart{tileset: tileset})
+	return &(*parts)[len(*parts)-1]
+}
+
+// Draw the animated tiles of a chunk at their current frame
+func (l *TileLayer) drawAnimated(chunk *tileChunk, local Matrix) {
+	m := l.tilemap
+	for i := range chunk.frames {
+		chunk.frames[i].vertices = chunk.frames[i].vertices[:0]
+		chunk.frames[i].indices = chunk.frames[i].indices[:0]
+	}
+	for _, cell := range chunk.animated {
+		tile := l.Tiles[cell]
+		tileset, index := m.tilesetFor(tile)
+		if tileset == nil {
+			continue
+		}
+		if anim := tileset.animations[index]; anim != nil {
+			index = anim.frameAt(m.Time)
+		}
+		part := chunkPart(&chunk.frames, tileset)
+		part.vertices, part.indices = m.appendTile(part.vertices, part.indices, tileset, index, tile, cell%m.Width, cell/m.Width, l.Tint)
+	}
+	for _, part := range chunk.frames {
+		if len(part.indices) > 0 {
+			l.drawPart(part.tileset, local, part.vertices, part.indices)
+		}
+	}
+}
+
+// Queue layer geometry under the layer's transform
+func (l *TileLayer) drawPart(tileset *Tileset, local Matrix, vertices []float32, indices []uint32) {
+	state := currentState(TextureVertex, Triangles, tileset.Image.TextureID)
+	state.transform = state.transform.Mul(local)
+	batchAppend(state, vertices, indices)
+}
+
+// Add the quad for a tile in cell (x, y), in layer pixels
+// Tiles bigger than the grid sit on the cell's bottom-left corner like in Tiled.
+func (m *TileMap) appendTile(vertices []float32, indices []uint32, tileset *Tileset, index int, tile uint32, x, y int, tint Color) ([]float32, []uint32) {
+	src := tileset.TileRect(index)
+	dst := Rect(
+		float32(x*m.TileWidth)+tileset.Offset.X,
+		float32((y+1)*m.TileHeight)-src.Height+tileset.Offset.Y,
+		src.Width, src.Height)
+	return appendTileQuad(vertices, indices, tileset.Image, src, tile, dst, tint)
+}
+
+// Add a quad showing src at dst with the tile's flip flags
+func appendTileQuad(vertices []float32, indices []uint32, img *Image, src Rectangle, tile uint32, dst Rectangle, tint Color) ([]float32, []uint32) {
+	iw, ih := float32(img.Width), float32(img.Height)
+	u := [2]float32{src.X / iw, (src.X + src.Width) / iw}
+	v := [2]float32{1 - src.Y/ih, 1 - (src.Y+src.Height)/ih} // rows are stored bottom-up
+
+	base := uint32(len(vertices) / 8)
+	corners := [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for _, c := range corners {
+		// Undo the flips to find which corner of the tile shows here
+		sx, sy := c[0], c[1]
+		if tile&TileFlipX != 0 {
+			sx = 1 - sx
+		}
+		if tile&TileFlipY != 0 {
+			sy = 1 - sy
+		}
+		if tile&TileFlipDiagonal != 0 {
+			sx, sy = sy, sx
+		}
+		vertices = append(vertices, dst.X+float32(c[0])*dst.Width, dst.Y+float32(c[1])*dst.Height, u[sx], v[sy], tint.R, tint.G, tint.B, tint.A)
+	}
+	indices = append(indices, base, base+1, base+2, base+2, base+3, base)
+	return vertices, indices
+}
@@ -0,0 +1,77 @@
+package graphics
+
+import (
+	"math"
+)
+
+// Transform stack state
+// The current transform is applied to every point passed to a Draw* function.
+var (
+	modelMatrix = MatrixIdentity()
+	modelStack  []Matrix
+)
+
+// PushTransform saves the current transform, restore it with PopTransform
+func PushTransform() {
+	modelStack = append(modelStack, modelMatrix)
+}
+
+// PopTransform restores the transform saved by the last PushTransform
+func PopTransform() {
+	if len(modelStack) == 0 {
+		modelMatrix = MatrixIdentity()
+		return
+	}
+	modelMatrix = modelStack[len(modelStack)-1]
+	modelStack = modelStack[:len(modelStack)-1]
+}
+
+// Translate moves everything drawn afterwards by (x, y)
+func Translate(x, y float32) {
+	modelMatrix = modelMatrix.Mul(MatrixTranslate(x, y))
+}
+
+// Rotate turns everything drawn afterwards around the current origin
+// Angle is in degrees, positive is counter-clockwise.
+func Rotate(degrees float32) {
+	modelMatrix = modelMatrix.Mul(MatrixRotate(degrees))
+}
+
+// Scale scales everything drawn afterwards around the current origin
+func Scale(sx, sy float32) {
+	modelMatrix = modelMatrix.Mul(MatrixScale(sx, sy))
+}
+
+// ResetTransform clears the current transform (the stack is kept)
+func ResetTransform() {
+	modelMatrix = MatrixIdentity()
+}
+
+// GetTransform returns the current transform
+func GetTransform() Matrix {
+	return modelMatrix
+}
+
+// Apply the current transform to a point
+// Draw functions pass every vertex through here before batching,
+// so geometry under different transforms still shares a draw call.
+func transformPoint(x, y float32) (float32, float32) {
+	return modelMatrix.Apply(x, y)
+}
+
+// Screen pixels covered by one unit under the camera and current transform
+// Curves use it to pick how many segments they need.
+func screenScale() float32 {
+	m := viewMatrix.Mul(modelMatrix)
+	return float32(math.Sqrt(math.Abs(float64(m[0]*m[4] - m[1]*m[3]))))
+}
+
+// The matrix uploaded to the shaders: camera, then surface pixels to OpenGL coordinates
+func projectionMatrix() Matrix {
+	projection := Matrix{
+		2 / float32(surfaceWidth), 0, 0,
+		0, -2 / float32(surfaceHeight), 0,
+		-1, 1, 1,
+	}
+	return projection.Mul(viewMatrix)
+}
@@ -0,0 +1,117 @@
+package graphics
+
+// Split a simple polygon into triangles by ear clipping
+// Returns indices into points, three per triangle. Repeated points
+// and points on a straight edge are handled, crossing edges are not.
+func triangulate(points []Vector2) []uint32 {
+	// Remaining corners, without repeats
+	remaining := make([]int, 0, len(points))
+	for i, p := range points {
+		if len(remaining) > 0 && points[remaining[len(remaining)-1]] == p {
+			continue
+		}
+		remaining = append(remaining, i)
+	}
+	if len(remaining) > 1 && points[remaining[0]] == points[remaining[len(remaining)-1]] {
+		remaining = remaining[:len(remaining)-1]
+	}
+	if len(remaining) < 3 {
+		return nil
+	}
+
+	// Winding of the whole polygon, ears turn the same way
+	var area float32
+	for i, r := range remaining {
+		next := remaining[(i+1)%len(remaining)]
+		area += points[r].Cross(points[next])
+	}
+	if area == 0 {
+		return nil
+	}
+	winding := float32(1)
+	if area < 0 {
+		winding = -1
+	}
+
+	indices := make([]uint32, 0, (len(remaining)-2)*3)
+	for len(remaining) > 3 {
+		ear := -1
+		for i := range remaining {
+			if isEar(points, remaining, i, winding) {
+				ear = i
+				break
+			}
+		}
+		if ear < 0 {
+			// Crossing edges or bad input, clip the least bad corner
+			ear = fallbackEar(points, remaining, winding)
+			if ear < 0 {
+				return indices // nothing convex is left, keep what is done
+			}
+		}
+		n := len(remaining)
+		prev, cur, next := remaining[(ear-1+n)%n], remaining[ear], remaining[(ear+1)%n]
+		indices = append(indices, uint32(prev), uint32(cur), uint32(next))
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+	return append(indices, uint32(remaining[0]), uint32(remaining[1]), uint32(remaining[2]))
+}
+
+// A corner is an ear when it is convex and no other corner lies in its triangle
+func isEar(points []Vector2, remaining []int, i int, winding float32) bool {
+	n := len(remaining)
+	a, b, c := points[remaining[(i-1+n)%n]], points[remaining[i]], points[remaining[(i+1)%n]]
+	if b.Sub(a).Cross(c.Sub(b))*winding <= 0 {
+		return false
+	}
+	for j, r := range remaining {
+		if j == i || j == (i-1+n)%n || j == (i+1)%n {
+			continue
+		}
+		p := points[r]
+		if p == a || p == b || p == c {
+			continue
+		}
+		if pointInTriangle(p, a, b, c, winding) {
+			return false
+		}
+	}
+	return true
+}
+
+// Corner to clip when there is no clean ear
+// A flat corner only makes an empty triangle, otherwise the convex
+// corner with the fewest other corners inside its triangle is picked.
+// Returns -1 when every corner is reflex.
+func fallbackEar(points []Vector2, remaining []int, winding float32) int {
+	n := len(remaining)
+	best, bestInside := -1, n+1
+	for i := range remaining {
+		a, b, c := points[remaining[(i-1+n)%n]], points[remaining[i]], points[remaining[(i+1)%n]]
+		turn := b.Sub(a).Cross(c.Sub(b)) * winding
+		if turn == 0 {
+			return i
+		}
+		if turn < 0 {
+			continue
+		}
+		inside := 0
+		for _, r := range remaining {
+			p := points[r]
+			if p != a && p != b && p != c && pointInTriangle(p, a, b, c, winding) {
+				inside++
+			}
+		}
+		if inside < bestInside {
+			best, bestInside = i, inside
+		}
+	}
+	return best
+}
+
+// Whether p is inside or on the edge of triangle abc with the given winding
+func pointInTriangle(p, a, b, c Vector2, winding float32) bool {
+	return b.Sub(a).Cross(p.Sub(a))*winding >= 0 &&
+		c.Sub(b).Cross(p.Sub(b))*winding >= 0 &&
+		a.Sub(c).Cross(p.Sub(c))*winding >= 0
+}
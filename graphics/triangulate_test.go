@@ -0,0 +1,84 @@
+package graphics
+
+import "testing"
+
+// Area enclosed by a simple polygon
+func polygonArea(points []Vector2) float32 {
+	var area float32
+	for i, p := range points {
+		area += p.Cross(points[(i+1)%len(points)])
+	}
+	return abs32(area) / 2
+}
+
+// Total area of indexed triangles, failing if one turns the wrong way
+func trianglesArea(t *testing.T, points []Vector2, indices []uint32, winding float32) float32 {
+	t.Helper()
+	var area float32
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := points[indices[i]], points[indices[i+1]], points[indices[i+2]]
+		signed := b.Sub(a).Cross(c.Sub(a)) / 2
+		if signed*winding < -1e-4 {
+			t.Errorf("triangle %v %v %v turns against the polygon", a, b, c)
+		}
+		area += abs32(signed)
+	}
+	return area
+}
+
+func reversed(points []Vector2) []Vector2 {
+	out := make([]Vector2, len(points))
+	for i, p := range points {
+		out[len(points)-1-i] = p
+	}
+	return out
+}
+
+func TestTriangulateArea(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Vector2
+	}{
+		{"square", []Vector2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}},
+		{"concave L", []Vector2{{0, 0}, {10, 0}, {10, 4}, {4, 4}, {4, 10}, {0, 10}}},
+		{"concave comb", []Vector2{{0, 0}, {12, 0}, {12, 10}, {10, 10}, {10, 2}, {7, 2}, {7, 10}, {5, 10}, {5, 2}, {2, 2}, {2, 10}, {0, 10}}},
+		{"repeated points", []Vector2{{0, 0}, {0, 0}, {10, 0}, {10, 10}, {10, 10}, {0, 10}, {0, 0}}},
+		{"collinear points", []Vector2{{0, 0}, {5, 0}, {10, 0}, {10, 5}, {10, 10}, {5, 10}, {0, 10}, {0, 5}}},
+		{"concave with collinear points", []Vector2{{0, 0}, {5, 0}, {10, 0}, {10, 10}, {5, 5}, {0, 10}, {0, 5}}},
+	}
+	for _, tt := range tests {
+		for _, w := range []struct {
+			name    string
+			points  []Vector2
+			winding float32
+		}{
+			{"clockwise", tt.points, 1}, // y points down, so positive cross turns clockwise on screen
+			{"counter-clockwise", reversed(tt.points), -1},
+		} {
+			t.Run(tt.name+"/"+w.name, func(t *testing.T) {
+				want := polygonArea(w.points)
+				got := trianglesArea(t, w.points, triangulate(w.points), w.winding)
+				if abs32(got-want) > 1e-3 {
+					t.Errorf("triangles cover %v, polygon area is %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTriangulateDegenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Vector2
+	}{
+		{"empty", nil},
+		{"two points", []Vector2{{0, 0}, {1, 1}}},
+		{"one point repeated", []Vector2{{3, 3}, {3, 3}, {3, 3}}},
+		{"line", []Vector2{{0, 0}, {5, 0}, {10, 0}}},
+	}
+	for _, tt := range tests {
+		if indices := triangulate(tt.points); len(indices) != 0 {
+			t.Errorf("%s: got %d indices, want none", tt.name, len(indices))
+		}
+	}
+}
@@ -1,41 +1,31 @@
 package graphics
 
-import (
-	"github.com/go-gl/gl/v3.3-core/gl"
-	"unsafe"
-)
-
-// Convert screen coordinates to OpenGL coordinates
-func screenToGL(x, y float32) (float32, float32) {
-	glX := (x / float32(windowWidth)) * 2.0 - 1.0
-	glY := 1.0 - (y / float32(windowHeight)) * 2.0
-	return glX, glY
+// Transform a point and append it as a shape vertex (x, y, r, g, b, a)
+func appendShapeVertex(vertices []float32, x, y float32, color Color) []float32 {
+	x, y = transformPoint(x, y)
+	return append(vertices, x, y, color.R, color.G, color.B, color.A)
 }
 
-// Draw vertices using OpenGL
-func drawVertices(vertices []float32, count int32, mode uint32) {
-	gl.BindVertexArray(currentVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, currentVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(unsafe.Sizeof(vertices[0]))*len(vertices), gl.Ptr(vertices), gl.DYNAMIC_DRAW)
-	
-	gl.UseProgram(shaderProgram)
-	gl.DrawArrays(mode, 0, count)
+// Queue shape vertices (x, y, r, g, b, a) with indices into the batch
+// Triangles get a smooth edge when anti-aliasing is on.
+func drawShape(vertices []float32, indices []uint32, primitive Primitive) {
+	if antiAlias && primitive == Triangles {
+		vertices, indices = addFringe(vertices, indices)
+	}
+	queueShape(vertices, indices, primitive)
 }
 
-// Draw with indices 
-func drawIndexed(vertices []float32, indices []uint32) {
-	var EBO uint32
-	gl.GenBuffers(1, &EBO)
-	
-	gl.BindVertexArray(currentVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, currentVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(unsafe.Sizeof(vertices[0]))*len(vertices), gl.Ptr(vertices), gl.DYNAMIC_DRAW)
-	
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, EBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(unsafe.Sizeof(indices[0]))*len(indices), gl.Ptr(indices), gl.DYNAMIC_DRAW)
-	
-	gl.UseProgram(shaderProgram)
-	gl.DrawElements(gl.TRIANGLES, int32(len(indices)), gl.UNSIGNED_INT, nil)
-	
-	gl.DeleteBuffers(1, &EBO)
+// Queue shape vertices as they are
+// Under a custom shader shapes become white-textured vertices.
+func queueShape(vertices []float32, indices []uint32, primitive Primitive) {
+	if currentShader != nil {
+		textured := make([]float32, 0, len(vertices)/6*8)
+		for i := 0; i+6 <= len(vertices); i += 6 {
+			v := vertices[i : i+6]
+			textured = append(textured, v[0], v[1], 0, 0, v[2], v[3], v[4], v[5])
+		}
+		batchAppend(currentState(TextureVertex, primitive, whiteTexture), textured, indices)
+		return
+	}
+	batchAppend(currentState(ShapeVertex, primitive, 0), vertices, indices)
 }
@@ -0,0 +1,74 @@
+package graphics
+
+import (
+	"math"
+)
+
+// Vector2 is a point or direction in 2D
+type Vector2 struct {
+	X, Y float32
+}
+
+// Vec2 is a short way to write Vector2{x, y}
+func Vec2(x, y float32) Vector2 {
+	return Vector2{x, y}
+}
+
+// Add returns v + o
+func (v Vector2) Add(o Vector2) Vector2 {
+	return Vector2{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o
+func (v Vector2) Sub(o Vector2) Vector2 {
+	return Vector2{v.X - o.X, v.Y - o.Y}
+}
+
+// Scale returns v multiplied by s
+func (v Vector2) Scale(s float32) Vector2 {
+	return Vector2{v.X * s, v.Y * s}
+}
+
+// Dot returns the dot product of v and o
+func (v Vector2) Dot(o Vector2) float32 {
+	return v.X*o.X + v.Y*o.Y
+}
+
+// Cross returns the z of the 3D cross product, positive when o turns clockwise from v on screen
+func (v Vector2) Cross(o Vector2) float32 {
+	return v.X*o.Y - v.Y*o.X
+}
+
+// Length returns the length of v
+func (v Vector2) Length() float32 {
+	return float32(math.Hypot(float64(v.X), float64(v.Y)))
+}
+
+// Normalize returns v with length 1, or zero if v is zero
+func (v Vector2) Normalize() Vector2 {
+	l := v.Length()
+	if l == 0 {
+		return Vector2{}
+	}
+	return Vector2{v.X / l, v.Y / l}
+}
+
+// Lerp returns the point t of the way from v to o
+func (v Vector2) Lerp(o Vector2, t float32) Vector2 {
+	return Vector2{v.X + (o.X-v.X)*t, v.Y + (o.Y-v.Y)*t}
+}
+
+// Rectangle is an area in pixels, X and Y at its top-left corner
+type Rectangle struct {
+	X, Y, Width, Height float32
+}
+
+// Rect is a short way to write Rectangle{x, y, width, height}
+func Rect(x, y, width, height float32) Rectangle {
+	return Rectangle{x, y, width, height}
+}
+
+// Contains reports whether the point is inside the rectangle
+func (r Rectangle) Contains(x, y float32) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.Width && y < r.Y+r.Height
+}
@@ -0,0 +1,67 @@
+//go:build !pixu_headless
+
+package graphics
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// The window, nil when running headless
+var window *glfw.Window
+
+// Initialize for graphics system
+// Opens a window with an OpenGL 3.3 context and draws with the GL backend.
+func Init(width, height int, title string) error {
+	windowWidth, windowHeight = width, height
+	surfaceWidth, surfaceHeight = width, height
+
+	if err := glfw.Init(); err != nil {
+		return err
+	}
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	if msaaSamples > 0 {
+		glfw.WindowHint(glfw.Samples, msaaSamples)
+	}
+
+	var err error
+	window, err = glfw.CreateWindow(width, height, title, nil, nil)
+	if err != nil {
+		return err
+	}
+	window.MakeContextCurrent()
+	setupInput(window)
+
+	// Setup viewport and OpenGL settings
+	backend, err = newGLBackend(width, height)
+	if err != nil {
+		return err
+	}
+
+	return initResources()
+}
+
+func windowShouldClose() bool {
+	if window == nil {
+		return false // headless, the caller decides when to stop
+	}
+	return window.ShouldClose()
+}
+
+// Show the frame and read new input events
+func swapWindow() {
+	if window != nil {
+		window.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+func closeWindow() {
+	if window == nil {
+		return
+	}
+	glfw.Terminate()
+	window = nil
+}
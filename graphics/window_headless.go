@@ -0,0 +1,17 @@
+//go:build pixu_headless
+
+package graphics
+
+import "errors"
+
+// Init needs a window, which builds with the pixu_headless tag leave out
+// Use InitHeadless instead.
+func Init(width, height int, title string) error {
+	return errors.New("graphics: built with pixu_headless, there is no window, use InitHeadless")
+}
+
+func windowShouldClose() bool { return false }
+
+func swapWindow() {}
+
+func closeWindow() {}